@@ -0,0 +1,147 @@
+// Command checkbloomfilter verify the base62 ID bloom filter still covers
+// every url in database.
+//
+// Missing member makes an existing link 404, so it exits with error when
+// any is found and -repair is not set. It also reports observed
+// false positive rate by probing ids that are never issued.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/TinyMurky/tinyurl/internal/setup"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	pkgbloomfilter "github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+	pkgdatabase "github.com/TinyMurky/tinyurl/pkg/database"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+	"github.com/TinyMurky/tinyurl/pkg/retry"
+)
+
+var (
+	batchSizeFlag  = flag.Int("batch-size", 1000, "number of ids read from database at once")
+	sampleRateFlag = flag.Float64("sample-rate", 1, "fraction (0, 1] of ids checked against filter")
+	probesFlag     = flag.Int("probes", 10000, "number of never-issued ids probed for false positive rate")
+	repairFlag     = flag.Bool("repair", false, "add missing ids back to filter")
+	maxMissingFlag = flag.Int("max-reported-missing", 100, "max number of missing ids printed")
+)
+
+type config struct {
+	Database     pkgdatabase.Config
+	BloomFilter  pkgbloomfilter.Config
+	StartupRetry retry.Config `env:", prefix=STARTUP_RETRY_"`
+}
+
+// DatabaseConfig return Database config
+func (c *config) DatabaseConfig() *pkgdatabase.Config {
+	return &c.Database
+}
+
+// BloomFilterConfig return the config of bloom filter
+func (c *config) BloomFilterConfig() *pkgbloomfilter.Config {
+	return &c.BloomFilter
+}
+
+// StartupRetryConfig return the retry config of connecting dependencies
+func (c *config) StartupRetryConfig() *retry.Config {
+	return &c.StartupRetry
+}
+
+func main() {
+	flag.Parse()
+
+	ctx, done := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	loadDotEnvIfNotLoaded()
+
+	logger := logging.NewLoggerFromEnv()
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		done()
+		if r := recover(); r != nil {
+			logger.Fatalw("check bloom filter panic", "panic", r)
+		}
+	}()
+
+	err := realMain(ctx)
+	done()
+
+	if err != nil {
+		log.Fatalf("check bloom filter failed: %s", err.Error())
+	}
+
+	logger.Info("check bloom filter complete successfully.")
+}
+
+func realMain(ctx context.Context) error {
+	var cfg config
+
+	env, err := setup.Setup(ctx, &cfg)
+	if err != nil {
+		return fmt.Errorf("setup.Setup: %w", err)
+	}
+	defer env.Close(ctx)
+
+	db := database.New(env.Database())
+	bf, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		return fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	result, err := bf.CheckBase62ID(ctx, db, bloomfilter.CheckOptions{
+		BatchSize:           *batchSizeFlag,
+		SampleRate:          *sampleRateFlag,
+		FalsePositiveProbes: *probesFlag,
+		Repair:              *repairFlag,
+		MaxReportedMissing:  *maxMissingFlag,
+	})
+	if err != nil {
+		return fmt.Errorf("CheckBase62ID: %w", err)
+	}
+
+	report, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+	fmt.Println(string(report))
+
+	if result.Missing > result.Repaired {
+		return fmt.Errorf("%d ids missing from bloom filter, run with -repair or rebuildbloomfilter", result.Missing-result.Repaired)
+	}
+
+	return nil
+}
+
+func loadDotEnvIfNotLoaded() {
+	mode := strings.TrimSpace(strings.ToLower(os.Getenv("RUN_MODE")))
+	isEnvLoaded := mode != ""
+
+	if !isEnvLoaded {
+		// it will be where the binary is located
+		exePath, err := os.Executable()
+		if err != nil {
+			panic(err)
+		}
+
+		exeDir := filepath.Dir(exePath)
+
+		envPath := filepath.Join(exeDir, "../../.env")
+		// load from .env
+		if err := godotenv.Load(envPath); err != nil {
+			panicMsg := fmt.Sprintf("Warning: failed to load .env file from path %q: %v\n", envPath, err)
+			panic(panicMsg)
+		}
+	}
+}
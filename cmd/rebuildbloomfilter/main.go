@@ -0,0 +1,123 @@
+// Command rebuildbloomfilter rebuild the base62 ID and longURL bloom filters from database.
+//
+// Run it when bloom filter is lost (ex: redis flushed), otherwise every
+// existing link returns 404 because read path trusts negative answer of filter.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/TinyMurky/tinyurl/internal/setup"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	pkgbloomfilter "github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+	pkgdatabase "github.com/TinyMurky/tinyurl/pkg/database"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+	"github.com/TinyMurky/tinyurl/pkg/retry"
+)
+
+var (
+	batchSizeFlag = flag.Int("batch-size", 1000, "number of ids added in one BF.MADD")
+)
+
+type config struct {
+	Database     pkgdatabase.Config
+	BloomFilter  pkgbloomfilter.Config
+	StartupRetry retry.Config `env:", prefix=STARTUP_RETRY_"`
+}
+
+// DatabaseConfig return Database config
+func (c *config) DatabaseConfig() *pkgdatabase.Config {
+	return &c.Database
+}
+
+// BloomFilterConfig return the config of bloom filter
+func (c *config) BloomFilterConfig() *pkgbloomfilter.Config {
+	return &c.BloomFilter
+}
+
+// StartupRetryConfig return the retry config of connecting dependencies
+func (c *config) StartupRetryConfig() *retry.Config {
+	return &c.StartupRetry
+}
+
+func main() {
+	flag.Parse()
+
+	ctx, done := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	loadDotEnvIfNotLoaded()
+
+	logger := logging.NewLoggerFromEnv()
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		done()
+		if r := recover(); r != nil {
+			logger.Fatalw("rebuild bloom filter panic", "panic", r)
+		}
+	}()
+
+	err := realMain(ctx)
+	done()
+
+	if err != nil {
+		log.Fatalf("rebuild bloom filter failed: %s", err.Error())
+	}
+
+	logger.Info("rebuild bloom filter complete successfully.")
+}
+
+func realMain(ctx context.Context) error {
+	var cfg config
+
+	env, err := setup.Setup(ctx, &cfg)
+	if err != nil {
+		return fmt.Errorf("setup.Setup: %w", err)
+	}
+	defer env.Close(ctx)
+
+	db := database.New(env.Database())
+	bf, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		return fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	if _, err := bf.Rebuild(ctx, db, *batchSizeFlag); err != nil {
+		return fmt.Errorf("Rebuild: %w", err)
+	}
+
+	return nil
+}
+
+func loadDotEnvIfNotLoaded() {
+	mode := strings.TrimSpace(strings.ToLower(os.Getenv("RUN_MODE")))
+	isEnvLoaded := mode != ""
+
+	if !isEnvLoaded {
+		// it will be where the binary is located
+		exePath, err := os.Executable()
+		if err != nil {
+			panic(err)
+		}
+
+		exeDir := filepath.Dir(exePath)
+
+		envPath := filepath.Join(exeDir, "../../.env")
+		// load from .env
+		if err := godotenv.Load(envPath); err != nil {
+			panicMsg := fmt.Sprintf("Warning: failed to load .env file from path %q: %v\n", envPath, err)
+			panic(panicMsg)
+		}
+	}
+}
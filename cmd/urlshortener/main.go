@@ -7,13 +7,19 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/joho/godotenv"
 
 	"github.com/TinyMurky/tinyurl/internal/setup"
 	"github.com/TinyMurky/tinyurl/internal/urlshortener"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
 	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/warmup"
+	pkgbloomfilter "github.com/TinyMurky/tinyurl/pkg/bloomfilter"
 	"github.com/TinyMurky/tinyurl/pkg/logging"
 	"github.com/TinyMurky/tinyurl/pkg/server"
 )
@@ -54,8 +60,50 @@ func realMain(ctx context.Context) error {
 	}
 	defer serverEnv.Close(ctx)
 
+	// background jobs flush state on stop (ex: click counts), so they are waited
+	// before env is closed. ctx is cancelled first, also when realMain returns an error.
+	var background sync.WaitGroup
+	defer background.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	alphabet, err := model.ParseAlphabet(config.IDGenerator.Alphabet)
+	if err != nil {
+		return fmt.Errorf("ID_GEN_ALPHABET: %w", err)
+	}
+	model.SetAlphabet(alphabet)
+
+	// cache is only an optimization, server still starts if warm up failed
+	if _, err := warmup.Run(ctx, &config, serverEnv); err != nil {
+		logger.Warnf("cache warm up: %s", err.Error())
+	}
+
+	bf, err := bloomfilter.New(ctx, serverEnv.BloomFilter(), config.BloomFilterConfig())
+	if err != nil {
+		return fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	db := database.New(serverEnv.Database())
+
+	// memory filter is empty after restart unless snapshot is loaded,
+	// every existing link would be 404 until it is rebuilt
+	if config.BloomFilter.FilterBackend() == pkgbloomfilter.BackendMemory {
+		if _, err := bf.RebuildIfEmpty(ctx, db, config.BloomFilterRebuildBatchSize); err != nil {
+			return fmt.Errorf("RebuildIfEmpty: %w", err)
+		}
+	}
+
+	bloomFilterMonitor := bloomfilter.NewCapacityMonitor(bf, db, &config)
+	go bloomFilterMonitor.Run(ctx)
+
 	urlShortenerServer := urlshortener.NewServer(&config, serverEnv)
 
+	routes, err := urlShortenerServer.Routes(ctx, &background)
+	if err != nil {
+		return fmt.Errorf("urlShortenerServer.Routes: %w", err)
+	}
+
 	srv, err := server.New(config.Port)
 	if err != nil {
 		return fmt.Errorf("server.New: %w", err)
@@ -63,7 +111,7 @@ func realMain(ctx context.Context) error {
 
 	logger.Infof("listening on :%s", config.Port)
 
-	return srv.ServeHTTPHandler(ctx, urlShortenerServer.Routes(ctx))
+	return srv.ServeHTTPHandler(ctx, routes)
 }
 
 func loadDotEnvIfNotLoaded() {
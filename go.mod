@@ -10,6 +10,7 @@ require (
 	github.com/redis/go-redis/v9 v9.17.2
 	github.com/sethvargo/go-envconfig v1.3.0
 	go.uber.org/zap v1.27.1
+	golang.org/x/sync v0.19.0
 	modernc.org/sqlite v1.40.1
 )
 
@@ -24,7 +25,6 @@ require (
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
-	golang.org/x/sync v0.19.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	modernc.org/libc v1.66.10 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+)
+
+// RequireBearerToken only let request with header
+// "Authorization: Bearer <token>" through.
+// All requests are rejected if token is empty,
+// so endpoint is disabled until token is configured.
+func RequireBearerToken(token string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				problem.NotFound(w, r)
+				return
+			}
+
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package problem write error responses as RFC 9457 problem details
+// (application/problem+json), with a machine readable error code.
+//
+// Detail is shown to clients, it should never contain internal error text.
+// Use Internal to log the internal error and respond a generic detail.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// ContentType of problem details
+const ContentType = "application/problem+json"
+
+// typePrefix make Code into the URI of problem type
+const typePrefix = "urn:tinyurl:problem:"
+
+// Code is machine readable error code, clients should switch on it instead of detail
+type Code string
+
+const (
+	// CodeInvalidRequest is request that does not match the API, ex: invalid body
+	CodeInvalidRequest Code = "invalid-request"
+	// CodeInvalidCode is short code that can not be decoded
+	CodeInvalidCode Code = "invalid-code"
+	// CodeNotFound is link or route that does not exist
+	CodeNotFound Code = "not-found"
+	// CodeBlockedURL is long url matched by blocklist
+	CodeBlockedURL Code = "blocked-url"
+	// CodeUnauthorized is admin request without valid token
+	CodeUnauthorized Code = "unauthorized"
+	// CodeMethodNotAllowed is request of wrong method
+	CodeMethodNotAllowed Code = "method-not-allowed"
+	// CodeConflict is request conflicting with current state, ex: rebuild is running
+	CodeConflict Code = "conflict"
+	// CodeInternal is failure of server, detail is always generic
+	CodeInternal Code = "internal"
+)
+
+// titles are short summaries of codes, same for every occurrence
+var titles = map[Code]string{
+	CodeInvalidRequest:   "Invalid request",
+	CodeInvalidCode:      "Invalid short code",
+	CodeNotFound:         "Not found",
+	CodeBlockedURL:       "URL is blocked",
+	CodeUnauthorized:     "Unauthorized",
+	CodeMethodNotAllowed: "Method not allowed",
+	CodeConflict:         "Conflict",
+	CodeInternal:         "Internal server error",
+}
+
+// Problem is RFC 9457 problem details with extension member "code"
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     Code   `json:"code"`
+}
+
+// New create problem of code, instance is path of request
+func New(r *http.Request, status int, code Code, detail string) Problem {
+	return Problem{
+		Type:     typePrefix + string(code),
+		Title:    titles[code],
+		Status:   status,
+		Detail:   detail,
+		Instance: instance(r),
+		Code:     code,
+	}
+}
+
+// instance is path requested by client, r.URL.Path is stripped by
+// http.StripPrefix of parent routers so RequestURI is used first
+func instance(r *http.Request) string {
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		return u.Path
+	}
+	return r.URL.Path
+}
+
+// Write respond problem of code with detail
+func Write(w http.ResponseWriter, r *http.Request, status int, code Code, detail string) {
+	WriteProblem(w, r, New(r, status, code, detail))
+}
+
+// WriteProblem respond p
+func WriteProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logging.FromContext(r.Context()).Named("problem").Errorf("JSON encode err: %s", err.Error())
+	}
+}
+
+// Internal log err with msg server-side only, and respond 500 with generic detail
+func Internal(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	logging.FromContext(r.Context()).Named("problem").Errorw(msg,
+		"error", err,
+		"method", r.Method,
+		"path", instance(r),
+	)
+
+	Write(w, r, http.StatusInternalServerError, CodeInternal, "")
+}
+
+// NotFound respond 404, it can be used as handler of unmatched routes
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusNotFound, CodeNotFound, "")
+}
+
+// MethodNotAllowed respond 405 with Allow header
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, allow string) {
+	w.Header().Set("Allow", allow)
+	Write(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "")
+}
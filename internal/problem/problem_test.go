@@ -0,0 +1,75 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/shortUrl/abc?x=1", nil)
+	// parent router stripped prefix
+	r.URL.Path = "/shortUrl/abc"
+	w := httptest.NewRecorder()
+
+	Write(w, r, http.StatusBadRequest, CodeInvalidCode, "id is not a valid short code")
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, ContentType)
+	}
+
+	var p Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	want := Problem{
+		Type:     "urn:tinyurl:problem:invalid-code",
+		Title:    "Invalid short code",
+		Status:   http.StatusBadRequest,
+		Detail:   "id is not a valid short code",
+		Instance: "/api/v1/shortUrl/abc",
+		Code:     CodeInvalidCode,
+	}
+	if p != want {
+		t.Errorf("problem = %+v, want %+v", p, want)
+	}
+}
+
+func TestInternalHidesError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/data/shorten", nil)
+	w := httptest.NewRecorder()
+
+	Internal(w, r, "create url", errors.New("database is locked"))
+
+	var p Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if p.Status != http.StatusInternalServerError || p.Code != CodeInternal {
+		t.Errorf("problem = %+v, want 500 %s", p, CodeInternal)
+	}
+
+	if p.Detail != "" {
+		t.Errorf("detail = %q, internal error must not be shown", p.Detail)
+	}
+}
+
+func TestTitlesCoverCodes(t *testing.T) {
+	codes := []Code{
+		CodeInvalidRequest, CodeInvalidCode, CodeNotFound, CodeBlockedURL,
+		CodeUnauthorized, CodeMethodNotAllowed, CodeConflict, CodeInternal,
+	}
+	for _, code := range codes {
+		if titles[code] == "" {
+			t.Errorf("code %q has no title", code)
+		}
+	}
+}
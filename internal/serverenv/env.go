@@ -20,16 +20,21 @@ import (
 
 	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
 	"github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
 	"github.com/TinyMurky/tinyurl/pkg/database"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+	"github.com/TinyMurky/tinyurl/pkg/nodelease"
 	"github.com/TinyMurky/tinyurl/pkg/singleflight"
 )
 
 // ServerEnv represents latent environment configuration for servers in this application.
 type ServerEnv struct {
 	database     *database.DB
-	cache        *cache.Cache
-	bloomFilter  *bloomfilter.BloomFilter
+	cache        cache.Cache
+	bloomFilter  bloomfilter.Filter
 	singleFlight singleflight.Group
+	redisBreaker *circuitbreaker.Breaker
+	nodeLease    *nodelease.Lease
 }
 
 // Option defines function types to modify the ServerEnv on creation.
@@ -52,10 +57,31 @@ func (s *ServerEnv) Close(ctx context.Context) error {
 		return nil
 	}
 
+	logger := logging.FromContext(ctx)
+
+	// lease may be stored in database, so it is released first
+	if s.nodeLease != nil {
+		if err := s.nodeLease.Close(ctx); err != nil {
+			logger.Errorf("Closing node lease error: %s", err.Error())
+		}
+	}
+
 	if s.database != nil {
 		s.database.Close(ctx)
 	}
 
+	if s.cache != nil {
+		if err := s.cache.Close(); err != nil {
+			logger.Errorf("Closing cache error: %s", err.Error())
+		}
+	}
+
+	if s.bloomFilter != nil {
+		if err := s.bloomFilter.Close(); err != nil {
+			logger.Errorf("Closing bloom filter error: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -73,7 +99,7 @@ func (s *ServerEnv) Database() *database.DB {
 }
 
 // WithCache add cache to serverEnv
-func WithCache(c *cache.Cache) Option {
+func WithCache(c cache.Cache) Option {
 	return func(s *ServerEnv) *ServerEnv {
 		s.cache = c
 		return s
@@ -81,12 +107,12 @@ func WithCache(c *cache.Cache) Option {
 }
 
 // Cache get cache
-func (s *ServerEnv) Cache() *cache.Cache {
+func (s *ServerEnv) Cache() cache.Cache {
 	return s.cache
 }
 
 // WithBloomFilter add bloom filter to serverEnv
-func WithBloomFilter(bf *bloomfilter.BloomFilter) Option {
+func WithBloomFilter(bf bloomfilter.Filter) Option {
 	return func(s *ServerEnv) *ServerEnv {
 		s.bloomFilter = bf
 		return s
@@ -94,7 +120,7 @@ func WithBloomFilter(bf *bloomfilter.BloomFilter) Option {
 }
 
 // BloomFilter get Bloom Filter
-func (s *ServerEnv) BloomFilter() *bloomfilter.BloomFilter {
+func (s *ServerEnv) BloomFilter() bloomfilter.Filter {
 	return s.bloomFilter
 }
 
@@ -110,3 +136,29 @@ func WithSingleFlight(sf singleflight.Group) Option {
 func (s *ServerEnv) SingleFlight() singleflight.Group {
 	return s.singleFlight
 }
+
+// WithRedisCircuitBreaker add circuit breaker that guard redis to serverEnv
+func WithRedisCircuitBreaker(b *circuitbreaker.Breaker) Option {
+	return func(s *ServerEnv) *ServerEnv {
+		s.redisBreaker = b
+		return s
+	}
+}
+
+// RedisCircuitBreaker get circuit breaker that guard redis
+func (s *ServerEnv) RedisCircuitBreaker() *circuitbreaker.Breaker {
+	return s.redisBreaker
+}
+
+// WithNodeLease add leased snowflake node ID to serverEnv
+func WithNodeLease(l *nodelease.Lease) Option {
+	return func(s *ServerEnv) *ServerEnv {
+		s.nodeLease = l
+		return s
+	}
+}
+
+// NodeLease get leased snowflake node ID, nil if leasing is disabled
+func (s *ServerEnv) NodeLease() *nodelease.Lease {
+	return s.nodeLease
+}
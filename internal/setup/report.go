@@ -0,0 +1,60 @@
+package setup
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TinyMurky/tinyurl/pkg/retry"
+)
+
+// dependencyResult is the outcome of connecting to one dependency
+type dependencyResult struct {
+	name     string
+	attempts int
+	duration time.Duration
+	err      error
+}
+
+// startupReport records which dependency is ready and which one failed,
+// so operator can tell the cause of failed start up from one log block.
+type startupReport struct {
+	retryConfig *retry.Config
+	results     []dependencyResult
+}
+
+// connect call fn with retry and record the result of dependency name
+func (r *startupReport) connect(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	attempts, err := retry.Do(ctx, r.retryConfig, fn)
+
+	r.results = append(r.results, dependencyResult{
+		name:     name,
+		attempts: attempts,
+		duration: time.Since(start),
+		err:      err,
+	})
+
+	return err
+}
+
+func (r *startupReport) log(logger *zap.SugaredLogger) {
+	for _, result := range r.results {
+		if result.err != nil {
+			logger.Errorw("startup dependency failed",
+				"dependency", result.name,
+				"attempts", result.attempts,
+				"duration", result.duration.String(),
+				"error", result.err.Error(),
+			)
+			continue
+		}
+
+		logger.Infow("startup dependency ready",
+			"dependency", result.name,
+			"attempts", result.attempts,
+			"duration", result.duration.String(),
+		)
+	}
+}
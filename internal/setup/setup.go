@@ -24,8 +24,11 @@ import (
 	"github.com/TinyMurky/tinyurl/internal/serverenv"
 	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
 	"github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
 	"github.com/TinyMurky/tinyurl/pkg/database"
 	"github.com/TinyMurky/tinyurl/pkg/logging"
+	"github.com/TinyMurky/tinyurl/pkg/nodelease"
+	"github.com/TinyMurky/tinyurl/pkg/retry"
 	"github.com/TinyMurky/tinyurl/pkg/singleflight"
 )
 
@@ -50,6 +53,24 @@ type SingleFlightConfigProvider interface {
 	SingleFlightConfig() *singleflight.Config
 }
 
+// CircuitBreakerConfigProvider ensures that the environment config can provide a circuit breaker config.
+type CircuitBreakerConfigProvider interface {
+	CircuitBreakerConfig() *circuitbreaker.Config
+}
+
+// StartupRetryConfigProvider ensures that the environment config can provide
+// the retry config of connecting dependencies on start up.
+// Dependencies are connected once if it is not provided.
+type StartupRetryConfigProvider interface {
+	StartupRetryConfig() *retry.Config
+}
+
+// NodeLeaseConfigProvider ensures that the environment config can provide a node lease config.
+// Node ID is leased only if backend is set.
+type NodeLeaseConfigProvider interface {
+	NodeLeaseConfig() *nodelease.Config
+}
+
 // Setup runs common initialization code for all servers. See SetupWith.
 func Setup(ctx context.Context, config any) (*serverenv.ServerEnv, error) {
 	//logger := logging.FromContext(ctx)
@@ -62,6 +83,9 @@ func Setup(ctx context.Context, config any) (*serverenv.ServerEnv, error) {
 // responsible for establishing database connections and
 // accessing app configs. The provided interface must implement the various
 // interfaces.
+//
+// Each dependency is connected with retry, and a report of which
+// dependency is ready or failed is logged before it returns.
 func SetupWith(ctx context.Context, config any, l envconfig.Lookuper) (*serverenv.ServerEnv, error) {
 	logger := logging.FromContext(ctx)
 
@@ -81,14 +105,37 @@ func SetupWith(ctx context.Context, config any, l envconfig.Lookuper) (*serveren
 		return nil, fmt.Errorf("error loading environment variables: %w", err)
 	}
 
+	report := &startupReport{
+		retryConfig: &retry.Config{MaxAttempts: 1},
+	}
+
+	if provider, ok := config.(StartupRetryConfigProvider); ok {
+		report.retryConfig = provider.StartupRetryConfig()
+	}
+
+	defer report.log(logger)
+
+	// dependencies connected before a failure are closed
+	fail := func(err error) (*serverenv.ServerEnv, error) {
+		serverenv.New(ctx, serverEnvOpts...).Close(ctx)
+		return nil, err
+	}
+
+	// database is kept for node lease
+	var db *database.DB
+
 	if provider, ok := config.(DatabaseConfigProvider); ok {
 		logger.Info("configuring database")
 		dbConfig := provider.DatabaseConfig()
 
-		db, err := database.NewFromEnv(ctx, dbConfig)
+		err := report.connect(ctx, "database", func(ctx context.Context) error {
+			var err error
+			db, err = database.NewFromEnv(ctx, dbConfig)
+			return err
+		})
 
 		if err != nil {
-			return nil, fmt.Errorf("unable to connect to database: %w", err)
+			return fail(fmt.Errorf("unable to connect to database: %w", err))
 		}
 
 		serverEnvOpt := serverenv.WithDatabase(db)
@@ -99,13 +146,18 @@ func SetupWith(ctx context.Context, config any, l envconfig.Lookuper) (*serveren
 		logger.Info("configuring cache")
 		cacheConfig := provider.CacheConfig()
 
-		cache, err := cache.NewFromEnv(ctx, cacheConfig)
+		var c cache.Cache
+		err := report.connect(ctx, "cache", func(ctx context.Context) error {
+			var err error
+			c, err = cache.NewFromEnv(ctx, cacheConfig)
+			return err
+		})
 
 		if err != nil {
-			return nil, fmt.Errorf("unable to connect to cache: %w", err)
+			return fail(fmt.Errorf("unable to connect to cache: %w", err))
 		}
 
-		serverEnvOpt := serverenv.WithCache(cache)
+		serverEnvOpt := serverenv.WithCache(c)
 		serverEnvOpts = append(serverEnvOpts, serverEnvOpt)
 	}
 
@@ -113,16 +165,42 @@ func SetupWith(ctx context.Context, config any, l envconfig.Lookuper) (*serveren
 		logger.Info("configuring bloom filter")
 		bloomFilterConfig := provider.BloomFilterConfig()
 
-		bloomFilter, err := bloomfilter.NewFromEnv(ctx, bloomFilterConfig)
+		var bloomFilter bloomfilter.Filter
+		err := report.connect(ctx, "bloom filter", func(ctx context.Context) error {
+			var err error
+			bloomFilter, err = bloomfilter.NewFromEnv(ctx, bloomFilterConfig)
+			return err
+		})
 
 		if err != nil {
-			return nil, fmt.Errorf("unable to connect to cache: %w", err)
+			return fail(fmt.Errorf("unable to connect to bloom filter: %w", err))
 		}
 
 		serverEnvOpt := serverenv.WithBloomFilter(bloomFilter)
 		serverEnvOpts = append(serverEnvOpts, serverEnvOpt)
 	}
 
+	if provider, ok := config.(NodeLeaseConfigProvider); ok && provider.NodeLeaseConfig().Enabled() {
+		logger.Info("configuring node lease")
+		nodeLeaseConfig := provider.NodeLeaseConfig()
+
+		var lease *nodelease.Lease
+		err := report.connect(ctx, "node lease", func(ctx context.Context) error {
+			var err error
+			lease, err = nodelease.NewFromEnv(ctx, nodeLeaseConfig, db)
+			return err
+		})
+
+		if err != nil {
+			return fail(fmt.Errorf("unable to lease node ID: %w", err))
+		}
+
+		logger.Infof("leased node ID %d as %s", lease.NodeID(), lease.Owner())
+
+		serverEnvOpt := serverenv.WithNodeLease(lease)
+		serverEnvOpts = append(serverEnvOpts, serverEnvOpt)
+	}
+
 	if provider, ok := config.(SingleFlightConfigProvider); ok {
 		logger.Info("configuring singleflight")
 		sfConfig := provider.SingleFlightConfig()
@@ -132,5 +210,14 @@ func SetupWith(ctx context.Context, config any, l envconfig.Lookuper) (*serveren
 		serverEnvOpts = append(serverEnvOpts, serverEnvOpt)
 	}
 
+	if provider, ok := config.(CircuitBreakerConfigProvider); ok {
+		logger.Info("configuring redis circuit breaker")
+		cbConfig := provider.CircuitBreakerConfig()
+		breaker := circuitbreaker.New(ctx, "redis", cbConfig)
+
+		serverEnvOpt := serverenv.WithRedisCircuitBreaker(breaker)
+		serverEnvOpts = append(serverEnvOpts, serverEnvOpt)
+	}
+
 	return serverenv.New(ctx, serverEnvOpts...), nil
 }
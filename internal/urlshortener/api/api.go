@@ -3,7 +3,10 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/TinyMurky/tinyurl/internal/serverenv"
 	v1 "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1"
@@ -29,12 +32,19 @@ func NewAPIHandler(cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) *Ha
 // Handler constructs and returns an http.Handler with all API routes registered.
 // This handler serves as the entry point for API traffic and can be mounted
 // onto a parent router.
-func (a *Handler) Handler() http.Handler {
+// ctx live as long as the server, background jobs of handlers stop when it is done.
+// See v1.Handler.Handler for background.
+func (a *Handler) Handler(ctx context.Context, background *sync.WaitGroup) (http.Handler, error) {
 	router := http.NewServeMux()
 
 	v1Router := v1.NewV1Handler(a.config, a.env)
 
-	router.Handle("/v1/", http.StripPrefix("/v1", v1Router.Handler()))
+	v1Handler, err := v1Router.Handler(ctx, background)
+	if err != nil {
+		return nil, fmt.Errorf("v1: %w", err)
+	}
+
+	router.Handle("/v1/", http.StripPrefix("/v1", v1Handler))
 
-	return router
+	return router, nil
 }
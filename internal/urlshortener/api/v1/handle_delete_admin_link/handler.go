@@ -0,0 +1,141 @@
+// Package handledeleteadminlink delete a short url, and remove it from
+// cache and, if BLOOM_FILTER_TYPE is cuckoo, from the base62 ID filter
+package handledeleteadminlink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/cache"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	idgenerator "github.com/TinyMurky/tinyurl/internal/urlshortener/id_generator"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+type response struct {
+	Success bool   `json:"success"`
+	ID      string `json:"id,omitempty"`
+	// RemovedFromFilter is false if filter is bloom filter or removal failed,
+	// the id keeps passing filter check until bloom filter is rebuilt.
+	RemovedFromFilter bool `json:"removed_from_filter"`
+}
+
+// Handler encapsulates the dependencies required for deleting short url.
+type Handler struct {
+	config       *urlshortenerconfig.Config
+	env          *serverenv.ServerEnv
+	cache        *cache.URLShortenerCache
+	bloomFilter  *bloomfilter.URLShortenerBloomFilter
+	db           *database.URLShortenerDB
+	strategy     idgenerator.Strategy
+	redisBreaker *circuitbreaker.Breaker
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// New will return http.Handler that delete short url
+func New(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (*Handler, error) {
+	bloomFilter, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		return nil, fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	db := database.New(env.Database())
+
+	strategy, err := idgenerator.NewStrategy(cfg, env.NodeLease(), db)
+	if err != nil {
+		return nil, fmt.Errorf("idgenerator.NewStrategy: %w", err)
+	}
+
+	return &Handler{
+		config:       cfg,
+		env:          env,
+		cache:        cache.New(env.Cache()),
+		bloomFilter:  bloomFilter,
+		db:           db,
+		strategy:     strategy,
+		redisBreaker: env.RedisCircuitBreaker(),
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.FromContext(ctx).Named("handle_delete_admin_link")
+
+	if r.Method != http.MethodDelete {
+		problem.MethodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	u, err := model.NewURLFromCode(r.PathValue("id"), h.strategy)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidCode, "id is not a valid short code")
+		return
+	}
+
+	deleted, err := h.db.DeleteURL(ctx, u.ID)
+	if err != nil {
+		problem.Internal(w, r, "DeleteURL", err)
+		return
+	}
+
+	if !deleted {
+		problem.NotFound(w, r)
+		return
+	}
+
+	// database is the source of truth, cache and filter failures only log
+	err = h.redisBreaker.Do(func() error {
+		return h.cache.DelURL(ctx, u)
+	})
+	if err != nil {
+		logger.Errorf("cache DelURL of %s failed, it is served until cache expired: %s", u.GetIDBase62(), err.Error())
+	}
+
+	removedFromFilter := h.removeFromFilter(ctx, u, logger)
+
+	res := response{
+		Success:           true,
+		ID:                h.strategy.Encode(u.ID),
+		RemovedFromFilter: removedFromFilter,
+	}
+	sendJSONResponse(w, http.StatusOK, res, logger)
+}
+
+func (h *Handler) removeFromFilter(ctx context.Context, u model.URL, logger *zap.SugaredLogger) bool {
+	err := h.redisBreaker.Do(func() error {
+		err := h.bloomFilter.RemoveURLBase62ID(ctx, u)
+		if errors.Is(err, bloomfilter.ErrRemoveNotSupported) {
+			// not a failure of redis
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		logger.Warnf("remove base62 ID %s from filter failed: %s", u.GetIDBase62(), err.Error())
+		return false
+	}
+
+	return h.bloomFilter.CanRemove()
+}
+
+func sendJSONResponse(w http.ResponseWriter, status int, data any, logger *zap.SugaredLogger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Errorf("JSON encode err: %s", err.Error())
+	}
+}
@@ -0,0 +1,89 @@
+// Package handlegetadminbloomfilterstats return capacity, fill ratio and
+// estimated false positive rate of base62 ID and longURL bloom filters
+package handlegetadminbloomfilterstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+type response struct {
+	Success bool `json:"success"`
+
+	// Stats is of base62 ID filter
+	Stats        *bloomfilter.FilterStats `json:"stats,omitempty"`
+	LongURLStats *bloomfilter.FilterStats `json:"long_url_stats,omitempty"`
+	Rebuilding   bool                     `json:"rebuilding"`
+}
+
+// Handler encapsulates the dependencies required for reading bloom filter stats.
+type Handler struct {
+	config      *urlshortenerconfig.Config
+	env         *serverenv.ServerEnv
+	bloomFilter *bloomfilter.URLShortenerBloomFilter
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// New will return http.Handler that return bloom filter stats
+func New(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (*Handler, error) {
+	bloomFilter, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		return nil, fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	return &Handler{
+		config:      cfg,
+		env:         env,
+		bloomFilter: bloomFilter,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.FromContext(ctx).Named("handle_get_admin_bloomfilter_stats")
+
+	if r.Method != http.MethodGet {
+		problem.MethodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	stats, err := h.bloomFilter.Base62IDStats(ctx)
+	if err != nil {
+		problem.Internal(w, r, "Base62IDStats", err)
+		return
+	}
+
+	longURLStats, err := h.bloomFilter.LongURLStats(ctx)
+	if err != nil {
+		problem.Internal(w, r, "LongURLStats", err)
+		return
+	}
+
+	res := response{
+		Success:      true,
+		Stats:        &stats,
+		LongURLStats: &longURLStats,
+		Rebuilding:   bloomfilter.IsRebuilding(),
+	}
+	sendJSONResponse(w, http.StatusOK, res, logger)
+}
+
+func sendJSONResponse(w http.ResponseWriter, status int, data any, logger *zap.SugaredLogger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Errorf("JSON encode err: %s", err.Error())
+	}
+}
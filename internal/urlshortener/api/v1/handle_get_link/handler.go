@@ -0,0 +1,183 @@
+// Package handlegetlink return details of a link without following the redirect
+package handlegetlink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	idgenerator "github.com/TinyMurky/tinyurl/internal/urlshortener/id_generator"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/lookup"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+type response struct {
+	Success    bool      `json:"success"`
+	ID         string    `json:"id"`
+	ShortURL   string    `json:"short_url"`
+	LongURL    string    `json:"long_url"`
+	CreatedAt  time.Time `json:"created_at"`
+	ClickCount int64     `json:"click_count"`
+}
+
+// Handler encapsulates the dependencies required for reading a link.
+type Handler struct {
+	config   *urlshortenerconfig.Config
+	env      *serverenv.ServerEnv
+	lookup   *lookup.Lookup
+	db       *database.URLShortenerDB
+	strategy idgenerator.Strategy
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// New will return http.Handler that return details of a link.
+// Link is found the same way as redirect, through bloom filter, cache and database.
+func New(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (*Handler, error) {
+	lookup, err := lookup.New(ctx, cfg, env)
+	if err != nil {
+		return nil, fmt.Errorf("lookup.New: %w", err)
+	}
+
+	db := database.New(env.Database())
+
+	strategy, err := idgenerator.NewStrategy(cfg, env.NodeLease(), db)
+	if err != nil {
+		return nil, fmt.Errorf("idgenerator.NewStrategy: %w", err)
+	}
+
+	return &Handler{
+		config:   cfg,
+		env:      env,
+		lookup:   lookup,
+		db:       db,
+		strategy: strategy,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.FromContext(ctx).Named("handle_get_link")
+
+	if r.Method != http.MethodGet {
+		problem.MethodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	u, err := model.NewURLFromCode(r.PathValue("id"), h.strategy)
+
+	// mistyped code can not exist, same as redirect
+	if errors.Is(err, idgenerator.ErrCheckCharMismatch) {
+		problem.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidCode, "id is not a valid short code")
+		return
+	}
+
+	u, err = h.lookup.Find(ctx, u)
+	if errors.Is(err, lookup.ErrNotFound) {
+		problem.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		problem.Internal(w, r, "lookup.Find", err)
+		return
+	}
+
+	// click count changes on every redirect, so it is not cached with url
+	clickCount, err := h.db.GetClickCount(ctx, u.ID)
+	if err != nil {
+		problem.Internal(w, r, "GetClickCount", err)
+		return
+	}
+
+	// cache entry of legacy schema has no created_at
+	if u.CreatedAt.IsZero() {
+		dbURL, err := h.db.GetFirstByID(ctx, u.ID)
+		if err != nil {
+			problem.Internal(w, r, "GetFirstByID", err)
+			return
+		}
+		u.CreatedAt = dbURL.CreatedAt
+	}
+
+	code := h.strategy.Encode(u.ID)
+
+	shortURL, err := model.ShortURL(h.config.ShortURLPrefix, code)
+	if err != nil {
+		problem.Internal(w, r, "model.ShortURL", err)
+		return
+	}
+
+	res := response{
+		Success:    true,
+		ID:         code,
+		ShortURL:   shortURL,
+		LongURL:    u.LongURL,
+		CreatedAt:  u.CreatedAt.UTC(),
+		ClickCount: clickCount,
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		problem.Internal(w, r, "json.Marshal", err)
+		return
+	}
+
+	etag := genETag(body)
+
+	// client has to revalidate, click count may change at any time
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", etag)
+
+	if matchETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(body); err != nil {
+		logger.Errorf("write body err: %s", err.Error())
+	}
+}
+
+// genETag return strong ETag of response body
+func genETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// matchETag report whether If-None-Match header matches etag.
+// Comparison is weak as RFC 9110 requires for If-None-Match,
+// so "W/" prefix is ignored.
+func matchETag(ifNoneMatch string, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
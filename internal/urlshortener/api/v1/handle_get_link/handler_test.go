@@ -0,0 +1,90 @@
+package handlegetlink
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/urlshortenertest"
+)
+
+func get(h *Handler, code string, ifNoneMatch string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/links/"+code, nil)
+	r.SetPathValue("id", code)
+	if ifNoneMatch != "" {
+		r.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestGetLinkETag(t *testing.T) {
+	ctx := context.Background()
+	cfg := urlshortenertest.NewConfig(t, nil)
+	env := urlshortenertest.NewServerEnv(t, cfg)
+
+	h, err := New(ctx, cfg, env)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	u := model.URL{ID: 123456789, LongURL: "https://example.com/a", CreatedAt: time.Now().UTC()}
+	db := database.New(env.Database())
+	if err := db.CreateURL(ctx, u); err != nil {
+		t.Fatalf("CreateURL: %v", err)
+	}
+
+	bf, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		t.Fatalf("bloomfilter.New: %v", err)
+	}
+	if err := bf.AddURL(ctx, u); err != nil {
+		t.Fatalf("AddURL: %v", err)
+	}
+
+	code := h.strategy.Encode(u.ID)
+
+	w := get(h, code, "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag %q, body %s", w.Code, etag, w.Body.String())
+	}
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		w := get(h, code, ifNoneMatch)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %q: status = %d, body %q, want 304 without body", ifNoneMatch, w.Code, w.Body.String())
+		}
+
+		if got := w.Header().Get("ETag"); got != etag {
+			t.Errorf("If-None-Match %q: ETag = %q, want %q", ifNoneMatch, got, etag)
+		}
+	}
+
+	if w := get(h, code, `"other"`); w.Code != http.StatusOK {
+		t.Errorf("unmatched If-None-Match: status = %d, want 200", w.Code)
+	}
+
+	// click changes body, old ETag no longer matches
+	if err := db.AddClicks(ctx, map[snowflake.SID]int64{u.ID: 1}); err != nil {
+		t.Fatalf("AddClicks: %v", err)
+	}
+
+	w = get(h, code, etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d after click, want 200", w.Code)
+	}
+
+	if got := w.Header().Get("ETag"); got == etag {
+		t.Errorf("ETag %q should change with click count", got)
+	}
+}
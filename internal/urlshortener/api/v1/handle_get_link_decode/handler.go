@@ -0,0 +1,127 @@
+// Package handlegetlinkdecode decode short code into snowflake ID and
+// tell when and on which node it was generated, for debugging
+package handlegetlinkdecode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	idgenerator "github.com/TinyMurky/tinyurl/internal/urlshortener/id_generator"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+type response struct {
+	Success bool   `json:"success"`
+	ID      string `json:"id,omitempty"`
+
+	// fields of decoded are inlined
+	*decoded
+}
+
+type decoded struct {
+	SnowflakeID int64     `json:"snowflake_id"`
+	Timestamp   time.Time `json:"timestamp"`
+	NodeID      int64     `json:"node_id"`
+	Sequence    int64     `json:"sequence"`
+
+	// Strategy is ID_GEN_STRATEGY of this server, parts of ID from
+	// other strategies than snowflake mean nothing
+	Strategy string `json:"strategy,omitempty"`
+	Exists   bool   `json:"exists"`
+}
+
+// Handler encapsulates the dependencies required for decoding short code.
+type Handler struct {
+	config   *urlshortenerconfig.Config
+	env      *serverenv.ServerEnv
+	db       *database.URLShortenerDB
+	strategy idgenerator.Strategy
+	epoch    time.Time
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// New will return http.Handler that decode short code.
+// It works for codes that do not exist, and says whether they do.
+func New(_ context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (*Handler, error) {
+	db := database.New(env.Database())
+
+	strategy, err := idgenerator.NewStrategy(cfg, env.NodeLease(), db)
+	if err != nil {
+		return nil, fmt.Errorf("idgenerator.NewStrategy: %w", err)
+	}
+
+	epoch, err := idgenerator.ParseEpoch(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("idgenerator.ParseEpoch: %w", err)
+	}
+
+	return &Handler{
+		config:   cfg,
+		env:      env,
+		db:       db,
+		strategy: strategy,
+		epoch:    epoch,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.FromContext(ctx).Named("handle_get_link_decode")
+
+	if r.Method != http.MethodGet {
+		problem.MethodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	code := r.PathValue("id")
+
+	u, err := model.NewURLFromCode(code, h.strategy)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidCode, "id is not a valid short code")
+		return
+	}
+
+	// database is the source of truth, bloom filter and cache are not needed for debugging
+	dbURL, err := h.db.GetFirstByID(ctx, u.ID)
+	if err != nil {
+		problem.Internal(w, r, "GetFirstByID", err)
+		return
+	}
+
+	parts := idgenerator.Decompose(u.ID, h.epoch)
+
+	res := response{
+		Success: true,
+		ID:      code,
+		decoded: &decoded{
+			SnowflakeID: int64(u.ID),
+			Timestamp:   parts.Time,
+			NodeID:      parts.NodeID,
+			Sequence:    parts.Sequence,
+			Strategy:    h.strategy.Name(),
+			Exists:      !dbURL.IsZero(),
+		},
+	}
+
+	sendJSONResponse(w, http.StatusOK, res, logger)
+}
+
+func sendJSONResponse(w http.ResponseWriter, status int, data any, logger *zap.SugaredLogger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Errorf("JSON encode err: %s", err.Error())
+	}
+}
@@ -0,0 +1,55 @@
+package handlegetlinks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/TinyMurky/snowflake"
+)
+
+// cursorVersion is bumped when fields of cursor change,
+// cursor of other version is rejected
+const cursorVersion = 1
+
+// cursor is position of next page and filters of the first page.
+// It is opaque to clients, filters of request are ignored once cursor is given,
+// so every page of a listing has the same filters.
+type cursor struct {
+	Version  int           `json:"v"`
+	BeforeID snowflake.SID `json:"b"`
+
+	CreatedAfter  time.Time `json:"ca,omitzero"`
+	CreatedBefore time.Time `json:"cb,omitzero"`
+	Host          string    `json:"h,omitempty"`
+}
+
+var errInvalidCursor = errors.New("cursor is invalid")
+
+// encode cursor as base64 url of JSON
+func (c cursor) encode() string {
+	c.Version = cursorVersion
+
+	// fields are plain values, Marshal can not fail
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, errInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return cursor{}, errInvalidCursor
+	}
+
+	if c.Version != cursorVersion || c.BeforeID == 0 {
+		return cursor{}, errInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,37 @@
+package handlegetlinks
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	want := cursor{
+		Version:      cursorVersion,
+		BeforeID:     112251924094390272,
+		CreatedAfter: time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+		Host:         "example.com",
+	}
+
+	got, err := decodeCursor(want.encode())
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+
+	if got != want {
+		t.Errorf("cursor = %+v, want %+v", got, want)
+	}
+
+	for _, s := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("{")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"v":2,"b":1}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"v":1}`)),
+	} {
+		if _, err := decodeCursor(s); !errors.Is(err, errInvalidCursor) {
+			t.Errorf("decodeCursor(%q): expect errInvalidCursor, got %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package handlegetlinks list links page by page in descending ID, for admins.
+// It is newest first for snowflake and counter strategy, whose IDs grow with time.
+package handlegetlinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	idgenerator "github.com/TinyMurky/tinyurl/internal/urlshortener/id_generator"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+type response struct {
+	Success bool   `json:"success"`
+	Links   []link `json:"links"`
+
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type link struct {
+	ID         string    `json:"id"`
+	ShortURL   string    `json:"short_url"`
+	LongURL    string    `json:"long_url"`
+	CreatedAt  time.Time `json:"created_at"`
+	ClickCount int64     `json:"click_count"`
+}
+
+// Handler encapsulates the dependencies required for listing links.
+type Handler struct {
+	config   *urlshortenerconfig.Config
+	env      *serverenv.ServerEnv
+	db       *database.URLShortenerDB
+	strategy idgenerator.Strategy
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// New will return http.Handler that list links from database.
+// Cache is not used, listing is for admins and need to see every link.
+func New(_ context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (*Handler, error) {
+	db := database.New(env.Database())
+
+	strategy, err := idgenerator.NewStrategy(cfg, env.NodeLease(), db)
+	if err != nil {
+		return nil, fmt.Errorf("idgenerator.NewStrategy: %w", err)
+	}
+
+	return &Handler{
+		config:   cfg,
+		env:      env,
+		db:       db,
+		strategy: strategy,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.FromContext(ctx).Named("handle_get_links")
+
+	if r.Method != http.MethodGet {
+		problem.MethodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	query, err := h.parseQuery(r.URL.Query())
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// one more than limit tells whether there is next page
+	limit := query.Limit
+	query.Limit++
+
+	listed, err := h.db.ListURLs(ctx, query)
+	if err != nil {
+		problem.Internal(w, r, "ListURLs", err)
+		return
+	}
+
+	res := response{
+		Success: true,
+		Links:   make([]link, 0, min(len(listed), limit)),
+	}
+
+	if len(listed) > limit {
+		listed = listed[:limit]
+
+		res.NextCursor = cursor{
+			BeforeID:      listed[limit-1].ID,
+			CreatedAfter:  query.CreatedAfter,
+			CreatedBefore: query.CreatedBefore,
+			Host:          query.Host,
+		}.encode()
+	}
+
+	for _, u := range listed {
+		code := h.strategy.Encode(u.ID)
+
+		shortURL, err := model.ShortURL(h.config.ShortURLPrefix, code)
+		if err != nil {
+			problem.Internal(w, r, "model.ShortURL", err)
+			return
+		}
+
+		res.Links = append(res.Links, link{
+			ID:         code,
+			ShortURL:   shortURL,
+			LongURL:    u.LongURL,
+			CreatedAt:  u.CreatedAt.UTC(),
+			ClickCount: u.ClickCount,
+		})
+	}
+
+	sendJSONResponse(w, http.StatusOK, res, logger)
+}
+
+// parseQuery read limit, cursor and filters of request.
+// Filters are taken from cursor if it is given.
+func (h *Handler) parseQuery(values url.Values) (database.ListURLsQuery, error) {
+	cfg := h.config.LinkList
+	query := database.ListURLsQuery{
+		Limit: cfg.PageSize,
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > cfg.MaxPageSize {
+			return query, fmt.Errorf("limit need to be between 1 and %d", cfg.MaxPageSize)
+		}
+		query.Limit = limit
+	}
+
+	if raw := values.Get("cursor"); raw != "" {
+		c, err := decodeCursor(raw)
+		if err != nil {
+			return query, err
+		}
+
+		query.BeforeID = c.BeforeID
+		query.CreatedAfter = c.CreatedAfter
+		query.CreatedBefore = c.CreatedBefore
+		query.Host = c.Host
+		return query, nil
+	}
+
+	var err error
+
+	if query.CreatedAfter, err = parseTime(values, "created_after"); err != nil {
+		return query, err
+	}
+
+	if query.CreatedBefore, err = parseTime(values, "created_before"); err != nil {
+		return query, err
+	}
+
+	// host is stored in lower case without port, see model.URL.Host
+	query.Host = strings.ToLower(values.Get("host"))
+
+	return query, nil
+}
+
+// parseTime parse RFC 3339 time of key, zero time if key is not given.
+// Fraction of second is rejected, created_at is stored in second and
+// an exclusive bound inside a second would drop the whole second.
+func parseTime(values url.Values, key string) (time.Time, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s need to be RFC 3339 time, ex: 2025-12-24T00:00:00Z", key)
+	}
+
+	if t.Nanosecond() != 0 {
+		return time.Time{}, fmt.Errorf("%s need to be in whole seconds, ex: 2025-12-24T00:00:00Z", key)
+	}
+
+	return t, nil
+}
+
+func sendJSONResponse(w http.ResponseWriter, status int, data any, logger *zap.SugaredLogger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Errorf("JSON encode err: %s", err.Error())
+	}
+}
@@ -0,0 +1,31 @@
+package handlegetlinks
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+)
+
+func TestParseQueryTime(t *testing.T) {
+	h := &Handler{config: &urlshortenerconfig.Config{
+		LinkList: urlshortenerconfig.LinkListConfig{PageSize: 50, MaxPageSize: 500},
+	}}
+
+	query, err := h.parseQuery(url.Values{"created_after": {"2025-12-24T08:00:00+08:00"}})
+	if err != nil {
+		t.Fatalf("parseQuery: %v", err)
+	}
+
+	if want := time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC); !query.CreatedAfter.Equal(want) {
+		t.Errorf("CreatedAfter = %v, want %v", query.CreatedAfter, want)
+	}
+
+	// bound inside a second would exclude the whole second
+	for _, raw := range []string{"2025-12-24T00:00:00.5Z", "2025-12-24"} {
+		if _, err := h.parseQuery(url.Values{"created_before": {raw}}); err == nil {
+			t.Errorf("created_before=%s: expect error", raw)
+		}
+	}
+}
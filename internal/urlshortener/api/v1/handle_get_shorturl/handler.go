@@ -3,19 +3,20 @@
 package handlegetshorturl
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
-	"time"
-
-	"github.com/redis/go-redis/v9"
 
+	"github.com/TinyMurky/tinyurl/internal/problem"
 	"github.com/TinyMurky/tinyurl/internal/serverenv"
-	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
-	"github.com/TinyMurky/tinyurl/internal/urlshortener/cache"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/blocklist"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/clicks"
 	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	idgenerator "github.com/TinyMurky/tinyurl/internal/urlshortener/id_generator"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/lookup"
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
-	"github.com/TinyMurky/tinyurl/internal/urlshortener/singleflight"
 	"github.com/TinyMurky/tinyurl/pkg/logging"
 )
 
@@ -23,123 +24,110 @@ import (
 // looking up original URL from id provided
 // It holds references to the configuration and server environment.
 type Handler struct {
-	config       *urlshortenerconfig.Config
-	env          *serverenv.ServerEnv
-	cache        *cache.URLShortenerCache
-	bloomFilter  *bloomfilter.URLShortenerBloomFilter
-	db           *database.URLShortenerDB
-	singleFlight *singleflight.Group
+	config *urlshortenerconfig.Config
+	env    *serverenv.ServerEnv
+	// lookup find url through bloom filter, cache and database
+	lookup *lookup.Lookup
+
+	// strategy decode short code into ID
+	strategy idgenerator.Strategy
+
+	// blocklist is checked again before redirect,
+	// so destination blocked after it was shortened stops resolving
+	blocklist *blocklist.Blocklist
+
+	// clicks count redirects, flushed to database in background
+	clicks *clicks.Counter
 }
 
 var _ http.Handler = (*Handler)(nil)
 
 // New will return http.Handler that can
-// get snowflake ID and return original longer url
-func New(cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) *Handler {
+// get snowflake ID and return original longer url.
+// blocklist and clicks are shared with other handlers, caller runs their background jobs.
+func New(
+	ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv,
+	blocklist *blocklist.Blocklist, clicks *clicks.Counter,
+) (*Handler, error) {
+	lookup, err := lookup.New(ctx, cfg, env)
+	if err != nil {
+		return nil, fmt.Errorf("lookup.New: %w", err)
+	}
 
-	cache := cache.New(env.Cache())
-	bloomFilter := bloomfilter.New(env.BloomFilter(), cfg.BloomFilterConfig())
 	db := database.New(env.Database())
-	sf := singleflight.New(env.SingleFlight())
 
-	return &Handler{
-		config:       cfg,
-		env:          env,
-		cache:        cache,
-		db:           db,
-		bloomFilter:  bloomFilter,
-		singleFlight: sf,
+	strategy, err := idgenerator.NewStrategy(cfg, env.NodeLease(), db)
+	if err != nil {
+		return nil, fmt.Errorf("idgenerator.NewStrategy: %w", err)
 	}
+
+	return &Handler{
+		config:    cfg,
+		env:       env,
+		lookup:    lookup,
+		strategy:  strategy,
+		blocklist: blocklist,
+		clicks:    clicks,
+	}, nil
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := logging.FromContext(ctx).Named("handel_get_shorturl")
-	cacheTTL := time.Millisecond * time.Duration(h.config.RedisCacheTTLInMiliSec)
 
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method Not allow", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w, r, http.MethodGet)
 		return
 	}
 
 	id := r.PathValue("id")
-
 	if len(id) == 0 {
-		http.Error(w, "Bad Request: id not provided", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidCode, "id is not provided")
 		return
 	}
 
-	u, err := model.NewURLFromBase62(id)
+	u, err := model.NewURLFromCode(id, h.strategy)
 
-	if err != nil {
-		http.Error(w, "invalid id: not base62", http.StatusBadRequest)
+	// mistyped code can not exist, reject it before bloom filter and cache
+	if errors.Is(err, idgenerator.ErrCheckCharMismatch) {
+		problem.NotFound(w, r)
 		return
 	}
 
-	// check bloom filter first
-	isURLExists, err := h.bloomFilter.IsURLBase62IDExist(ctx, u)
 	if err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		logger.Errorf("bloom filter IsURLBase62IDExist: %s", err.Error())
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidCode, "id is not a valid short code")
 		return
 	}
 
-	if !isURLExists {
-		http.Error(w, "not found", http.StatusNotFound)
+	u, err = h.lookup.Find(ctx, u)
+	if errors.Is(err, lookup.ErrNotFound) {
+		problem.NotFound(w, r)
 		logger.Errorf("ID not found: %s", u.GetIDBase62())
 		return
 	}
 
-	u, err = h.cache.GetLongURL(ctx, u)
-
-	if err != nil && !errors.Is(err, redis.Nil) {
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		logger.Errorf("cache GetLongURL: %s", err.Error())
-		return
-	}
-
-	if !u.IsEmptyLongURL() {
-		// 找到 cache 的資料
-		http.Redirect(w, r, u.LongURL, http.StatusMovedPermanently)
-		return
-	}
-
-	v, err, _ := h.singleFlight.Do(u.GetIDBase62(), func() (any, error) {
-		u, err := h.db.GetFirstByID(ctx, u.ID)
-		if err != nil {
-			return model.URL{}, err
-		}
-
-		if u.IsEmptyLongURL() {
-			return u, nil
-		}
-
-		err = h.cache.SetLongURL(ctx, u, cacheTTL)
-		if err != nil {
-			// If cache fails, we still return the URL but log the error.
-			// Returning error here would fail the request, which might be too harsh if DB worked.
-			// But sticking to original logic, let's return error if SetLongURL fails?
-			// The original logic returned 500 on SetLongURL error. Let's keep it consistency.
-			return model.URL{}, err
-		}
-
-		return u, nil
-	})
-
 	if err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		logger.Errorf("singleFlight error: %s", err.Error())
+		problem.Internal(w, r, "lookup.Find", err)
 		return
 	}
 
-	u = v.(model.URL)
+	h.redirect(w, r, u)
+	logger.Debug("method=", r.Method, "id=", id, "tinyURL=", u.LongURL)
+}
 
-	if u.IsEmptyLongURL() {
-		http.Error(w, "not found", http.StatusNotFound)
-		logger.Errorf("ID not found: %s", u.GetIDBase62())
+// redirect to LongURL of u, unless it is blocked.
+// Only redirect is counted as click.
+//
+// Redirect is 302 and not stored by browsers, so every visit comes back
+// to be counted, and a link blocked or deleted later stops resolving.
+func (h *Handler) redirect(w http.ResponseWriter, r *http.Request, u model.URL) {
+	if h.blocklist.IsBlocked(u.LongURL) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeBlockedURL, "destination of the link is blocked")
+		logging.FromContext(r.Context()).Named("handel_get_shorturl").Infof("blocked ID: %s", u.GetIDBase62())
 		return
 	}
 
-	http.Redirect(w, r, u.LongURL, http.StatusMovedPermanently)
-	logger.Debug("method=", r.Method, "id=", id, "tinyURL=", u.LongURL)
+	h.clicks.Add(u.ID)
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, u.LongURL, http.StatusFound)
 }
@@ -0,0 +1,153 @@
+package handlegetshorturl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/clicks"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/urlshortenertest"
+	pkgbloomfilter "github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+	"github.com/TinyMurky/tinyurl/pkg/cache"
+)
+
+// newHandler create handler on env, clicks are counted but not flushed
+func newHandler(t *testing.T, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) *Handler {
+	t.Helper()
+
+	h, err := New(context.Background(), cfg, env,
+		urlshortenertest.NewBlocklist(t, cfg, env), clicks.New(database.New(env.Database())))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return h
+}
+
+// createURL store u in database and bloom filter of env, code of u is returned
+func createURL(t *testing.T, h *Handler, env *serverenv.ServerEnv, u model.URL) string {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := database.New(env.Database()).CreateURL(ctx, u); err != nil {
+		t.Fatalf("CreateURL: %v", err)
+	}
+
+	bf, err := bloomfilter.New(ctx, env.BloomFilter(), h.config.BloomFilterConfig())
+	if err != nil {
+		t.Fatalf("bloomfilter.New: %v", err)
+	}
+
+	if err := bf.AddURL(ctx, u); err != nil {
+		t.Fatalf("AddURL: %v", err)
+	}
+
+	return h.strategy.Encode(u.ID)
+}
+
+func get(h *Handler, code string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/shortUrl/"+code, nil)
+	r.SetPathValue("id", code)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// Redirect must not be stored by browsers, otherwise later visits skip
+// click counting and blocklist, so it is 302 with no-store instead of 301.
+func TestRedirectNotStored(t *testing.T) {
+	cfg := urlshortenertest.NewConfig(t, nil)
+	env := urlshortenertest.NewServerEnv(t, cfg)
+
+	h := newHandler(t, cfg, env)
+
+	code := createURL(t, h, env, model.URL{ID: 1, LongURL: "https://example.com/a", CreatedAt: time.Now().UTC()})
+
+	w := get(h, code)
+	if w.Code != http.StatusFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusFound)
+	}
+
+	if got := w.Header().Get("Location"); got != "https://example.com/a" {
+		t.Errorf("Location = %q, want %q", got, "https://example.com/a")
+	}
+
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want %q", got, "no-store")
+	}
+}
+
+func TestRedirectBlocked(t *testing.T) {
+	ctx := context.Background()
+	cfg := urlshortenertest.NewConfig(t, nil)
+	env := urlshortenertest.NewServerEnv(t, cfg)
+
+	if err := env.Cache().Set(ctx, cfg.Blocklist.RedisKey, "blocked.example.com", time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	h := newHandler(t, cfg, env)
+
+	now := time.Now().UTC()
+	allowed := createURL(t, h, env, model.URL{ID: 1, LongURL: "https://example.com/a", CreatedAt: now})
+	blocked := createURL(t, h, env, model.URL{ID: 2, LongURL: "https://blocked.example.com/a", CreatedAt: now})
+
+	if w := get(h, allowed); w.Code/100 != 3 || w.Header().Get("Location") != "https://example.com/a" {
+		t.Errorf("allowed: status = %d, location %q", w.Code, w.Header().Get("Location"))
+	}
+
+	if w := get(h, blocked); w.Code != http.StatusForbidden {
+		t.Errorf("blocked: status = %d, want %d, body %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
+
+func TestCheckCharMismatchSkipsRedis(t *testing.T) {
+	ctx := context.Background()
+	cfg := urlshortenertest.NewConfig(t, map[string]string{"ID_GEN_CHECK_CHAR_ENABLED": "true"})
+
+	filter, err := pkgbloomfilter.NewFromEnv(ctx, cfg.BloomFilterConfig())
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	spyFilter := &urlshortenertest.SpyFilter{Filter: filter}
+	spyCache := &urlshortenertest.SpyCache{Cache: cache.NewMemoryCache(cfg.Cache.MemoryMaxEntries)}
+	env := urlshortenertest.NewServerEnv(t, cfg, serverenv.WithBloomFilter(spyFilter), serverenv.WithCache(spyCache))
+
+	h := newHandler(t, cfg, env)
+
+	code := createURL(t, h, env, model.URL{ID: 123456789, LongURL: "https://example.com/a", CreatedAt: time.Now().UTC()})
+
+	// change check character only, so the rest of code is still a valid ID
+	last := code[len(code)-1]
+	typo := code[:len(code)-1] + "a"
+	if last == 'a' {
+		typo = code[:len(code)-1] + "b"
+	}
+
+	spyFilter.Reset()
+	spyCache.Reset()
+
+	if w := get(h, typo); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d, body %s", w.Code, http.StatusNotFound, w.Body.String())
+	}
+
+	if n := spyFilter.Total() + spyCache.Total(); n != 0 {
+		t.Errorf("mistyped code made %d filter and cache calls, want 0", n)
+	}
+
+	// the spies are on the lookup path of a valid code
+	if w := get(h, code); w.Code/100 != 3 {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	if spyFilter.Total() == 0 || spyCache.Total() == 0 {
+		t.Errorf("valid code should go through filter and cache, got %d and %d calls", spyFilter.Total(), spyCache.Total())
+	}
+}
@@ -0,0 +1,101 @@
+// Package handlepostadminbloomfilterrebuild start rebuilding
+// base62 ID and longURL bloom filters from database in background
+package handlepostadminbloomfilterrebuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+type response struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// Handler encapsulates the dependencies required for rebuilding bloom filter.
+// Only one rebuild runs at a time in this process,
+// including the one started by capacity monitor.
+type Handler struct {
+	config      *urlshortenerconfig.Config
+	env         *serverenv.ServerEnv
+	bloomFilter *bloomfilter.URLShortenerBloomFilter
+	db          *database.URLShortenerDB
+
+	// serverCtx outlive the request, rebuild stop when server shutdown
+	serverCtx context.Context
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// New will return http.Handler that can start bloom filter rebuild
+func New(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (*Handler, error) {
+	bloomFilter, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		return nil, fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	db := database.New(env.Database())
+
+	return &Handler{
+		config:      cfg,
+		env:         env,
+		bloomFilter: bloomFilter,
+		db:          db,
+		serverCtx:   ctx,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context()).Named("handle_post_admin_bloomfilter_rebuild")
+
+	if r.Method != http.MethodPost {
+		problem.MethodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	// taken before responding, so two requests can not both get 202
+	release, ok := bloomfilter.TryStartRebuild()
+	if !ok {
+		problem.Write(w, r, http.StatusConflict, problem.CodeConflict, "bloom filter rebuild is already running")
+		return
+	}
+
+	go h.rebuild(release)
+
+	res := response{
+		Success: true,
+		Message: "bloom filter rebuild started",
+	}
+	sendJSONResponse(w, http.StatusAccepted, res, logger)
+}
+
+func (h *Handler) rebuild(release func()) {
+	defer release()
+
+	ctx := h.serverCtx
+	logger := logging.FromContext(ctx).Named("handle_post_admin_bloomfilter_rebuild")
+
+	if _, err := h.bloomFilter.RebuildStarted(ctx, h.db, h.config.BloomFilterRebuildBatchSize); err != nil {
+		logger.Errorf("RebuildStarted: %s", err.Error())
+	}
+}
+
+func sendJSONResponse(w http.ResponseWriter, status int, data any, logger *zap.SugaredLogger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Errorf("JSON encode err: %s", err.Error())
+	}
+}
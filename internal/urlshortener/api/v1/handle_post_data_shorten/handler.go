@@ -7,26 +7,31 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/TinyMurky/tinyurl/internal/problem"
 	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/blocklist"
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/cache"
 	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
 	idgenerator "github.com/TinyMurky/tinyurl/internal/urlshortener/id_generator"
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+	pkgdatabase "github.com/TinyMurky/tinyurl/pkg/database"
 	"github.com/TinyMurky/tinyurl/pkg/logging"
 )
 
+// maxCreateAttempts is how many IDs are tried when ID is taken by another url
+const maxCreateAttempts = 3
+
 type response struct {
 	Success  bool   `json:"success"`
-	Message  string `json:"message,omitempty"`
 	ShortURL string `json:"short_url,omitempty"`
 }
 
@@ -39,31 +44,51 @@ type Handler struct {
 	cache       *cache.URLShortenerCache
 	bloomFilter *bloomfilter.URLShortenerBloomFilter
 	db          *database.URLShortenerDB
-	idGenerator *idgenerator.Generator
+	strategy    idgenerator.Strategy
+	blocklist   *blocklist.Blocklist
+
+	// bloomFilterAdder queue bloom filter add for replay if redis is down
+	bloomFilterAdder *bloomfilter.AddReplayer
+	redisBreaker     *circuitbreaker.Breaker
 }
 
 var _ http.Handler = (*Handler)(nil)
 
 // New will return http.Handler that can
 // get snowflake ID and return original longer url
-func New(cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) *Handler {
+// Bloom filter adds that failed are replayed in background until ctx is done.
+// blocklist is shared with other handlers, caller runs its reload.
+func New(
+	ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv, blocklist *blocklist.Blocklist,
+) (*Handler, error) {
 	cache := cache.New(env.Cache())
-	bloomFilter := bloomfilter.New(env.BloomFilter(), cfg.BloomFilterConfig())
+	bloomFilter, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		return nil, fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	redisBreaker := env.RedisCircuitBreaker()
+	bloomFilterAdder := bloomfilter.NewAddReplayer(bloomFilter, redisBreaker, cfg.BloomFilterReplayQueueSize)
 	db := database.New(env.Database())
-	idGenerator, err := idgenerator.NewGenerator(cfg)
+	strategy, err := idgenerator.NewStrategy(cfg, env.NodeLease(), db)
 
 	if err != nil {
-		log.Fatalf("New handle_post_data_shorten new id generator: %s", err.Error())
+		return nil, fmt.Errorf("idgenerator.NewStrategy: %w", err)
 	}
 
+	go bloomFilterAdder.Run(ctx, cfg.BloomFilterReplayInterval)
+
 	return &Handler{
-		config:      cfg,
-		env:         env,
-		cache:       cache,
-		db:          db,
-		idGenerator: idGenerator,
-		bloomFilter: bloomFilter,
-	}
+		config:           cfg,
+		env:              env,
+		cache:            cache,
+		db:               db,
+		strategy:         strategy,
+		blocklist:        blocklist,
+		bloomFilter:      bloomFilter,
+		bloomFilterAdder: bloomFilterAdder,
+		redisBreaker:     redisBreaker,
+	}, nil
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -73,19 +98,19 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var contentType = "application/x-www-form-urlencoded"
 
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w, r, http.MethodPost)
 		return
 	}
 
 	if r.Header.Get("Content-Type") != contentType {
 		msg := fmt.Sprintf("Content-Type need to be %s", contentType)
-		http.Error(w, msg, http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, msg)
 		return
 	}
 
 	// parse form will parse query and form
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "failed to parse form")
 		return
 	}
 
@@ -93,14 +118,19 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	longURL := r.PostFormValue("long_url")
 
 	if longURL == "" {
-		msg := "long_url is required"
-		sendBadRequest(w, msg, logger)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "long_url is required")
 		return
 	}
 
 	if !isValidURL(longURL) {
 		msg := fmt.Sprintf("long_url %q is invalid", longURL)
-		sendBadRequest(w, msg, logger)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, msg)
+		return
+	}
+
+	if h.blocklist.IsBlocked(longURL) {
+		msg := fmt.Sprintf("long_url %q is blocked", longURL)
+		problem.Write(w, r, http.StatusForbidden, problem.CodeBlockedURL, msg)
 		return
 	}
 
@@ -108,27 +138,25 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		LongURL: longURL,
 	}
 
-	u, err := h.createURL(ctx, u)
+	u, created, err := h.createURL(ctx, u)
 
 	if err != nil {
-		msg := fmt.Sprintf("create url error: %s", err.Error())
-		sendInternalError(w, msg, logger)
+		problem.Internal(w, r, "create url", err)
 		return
 	}
 
-	err = h.bloomFilter.AddURLBase62ID(ctx, u)
-
-	if err != nil {
-		msg := fmt.Sprintf("add url base 62 ID to bloom filter error: %s", err.Error())
-		sendInternalError(w, msg, logger)
-		return
+	// existing url is already in filter, adding it again would need
+	// two removes from cuckoo filter when it is deleted.
+	// url is already in database, if bloom filter is down
+	// the add is queued for replay instead of failing the request
+	if created {
+		h.bloomFilterAdder.AddURL(ctx, u)
 	}
 
 	shortURL, err := h.genTinyURL(u)
 
 	if err != nil {
-		msg := fmt.Sprintf("gen tiny url error: %s", err.Error())
-		sendInternalError(w, msg, logger)
+		problem.Internal(w, r, "gen tiny url", err)
 		return
 	}
 
@@ -142,44 +170,111 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logger.Debug("method", r.Method, "response", res)
 }
 
-func (h *Handler) createURL(ctx context.Context, urlModel model.URL) (model.URL, error) {
-	cacheTTL := time.Millisecond * time.Duration(h.config.RedisCacheTTLInMiliSec)
+// createURL return url of longURL, created is false if longURL already exists.
+func (h *Handler) createURL(ctx context.Context, urlModel model.URL) (model.URL, bool, error) {
 
 	if urlModel.LongURL == "" {
-		return model.URL{}, errors.New("longURL is empty")
+		return model.URL{}, false, errors.New("longURL is empty")
 	}
 
-	dbURLModel, err := h.db.GetFirstByLongURL(ctx, urlModel.LongURL)
+	// brand-new longURL skip the query and go straight to insert
+	if h.isLongURLExist(ctx, urlModel) {
+		dbURLModel, err := h.db.GetFirstByLongURL(ctx, urlModel.LongURL)
 
-	if err != nil {
-		return model.URL{}, fmt.Errorf("database GetFirstByLongURL: %w", err)
-	}
+		if err != nil {
+			return model.URL{}, false, fmt.Errorf("database GetFirstByLongURL: %w", err)
+		}
 
-	// If exist just return
-	if !dbURLModel.IsZero() {
-		if err := h.cache.SetLongURL(ctx, dbURLModel, cacheTTL); err != nil {
-			return model.URL{}, fmt.Errorf("cache SetLongURL: %w", err)
+		// If exist just return
+		if !dbURLModel.IsZero() {
+			h.setURLToCache(ctx, dbURLModel)
+			return dbURLModel, false, nil
 		}
-		return dbURLModel, nil
 	}
 
-	newID, err := h.idGenerator.NextID()
+	// database store created_at in second, cached url need to be the same
+	urlModel.CreatedAt = time.Now().UTC().Truncate(time.Second)
 
-	if err != nil {
-		return model.URL{}, fmt.Errorf("idGenerator nextID: %w", err)
+	for attempt := 1; ; attempt++ {
+		newID, err := h.strategy.NextID(ctx, urlModel.LongURL)
+
+		if err != nil {
+			return model.URL{}, false, fmt.Errorf("strategy %s NextID: %w", h.strategy.Name(), err)
+		}
+
+		urlModel.ID = newID
+
+		err = h.db.CreateURL(ctx, urlModel)
+
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, pkgdatabase.ErrKeyConflict) {
+			return model.URL{}, false, fmt.Errorf("database CreateURL: %w", err)
+		}
+
+		// longURL created before longURL filter existed or by concurrent request
+		dbURLModel, err := h.db.GetFirstByLongURL(ctx, urlModel.LongURL)
+
+		if err != nil {
+			return model.URL{}, false, fmt.Errorf("database GetFirstByLongURL after conflict: %w", err)
+		}
+
+		if !dbURLModel.IsZero() {
+			h.setURLToCache(ctx, dbURLModel)
+			return dbURLModel, false, nil
+		}
+
+		// ID is taken by another url (ex: concurrent insert of random strategy), try next ID
+		if attempt >= maxCreateAttempts {
+			return model.URL{}, false, fmt.Errorf("database CreateURL: key conflict after %d attempts", attempt)
+		}
 	}
 
-	urlModel.ID = newID
+	h.setURLToCache(ctx, urlModel)
+
+	return urlModel, true, nil
+}
+
+// isLongURLExist check longURL filter through circuit breaker,
+// it returns true ("might exist") if filter is disabled or unavailable.
+func (h *Handler) isLongURLExist(ctx context.Context, u model.URL) bool {
+	logger := logging.FromContext(ctx).Named("handel_post_data_shorten")
 
-	if err := h.db.CreateURL(ctx, urlModel); err != nil {
-		return model.URL{}, fmt.Errorf("database CreateURL: %w", err)
+	if !h.config.LongURLFilterEnabled {
+		return true
 	}
 
-	if err := h.cache.SetLongURL(ctx, urlModel, cacheTTL); err != nil {
-		return model.URL{}, fmt.Errorf("cache SetLongURL: %w", err)
+	var isExist bool
+
+	err := h.redisBreaker.Do(func() error {
+		var err error
+		isExist, err = h.bloomFilter.IsLongURLExist(ctx, u)
+		return err
+	})
+
+	if err != nil {
+		logger.Warnf("longURL filter skipped: %s", err.Error())
+		return true
 	}
 
-	return urlModel, nil
+	return isExist
+}
+
+// setURLToCache set url to cache through circuit breaker.
+// Cache is only an optimization of read path, failure is logged only.
+func (h *Handler) setURLToCache(ctx context.Context, u model.URL) {
+	logger := logging.FromContext(ctx).Named("handel_post_data_shorten")
+	cacheTTL := time.Millisecond * time.Duration(h.config.RedisCacheTTLInMiliSec)
+
+	err := h.redisBreaker.Do(func() error {
+		return h.cache.SetURL(ctx, u, cacheTTL)
+	})
+
+	if err != nil {
+		logger.Warnf("cache SetURL skipped: %s", err.Error())
+	}
 }
 
 func (h *Handler) genTinyURL(u model.URL) (string, error) {
@@ -188,7 +283,7 @@ func (h *Handler) genTinyURL(u model.URL) (string, error) {
 		return "", fmt.Errorf("config.ShortURLPrefix %q is not valid", urlPath)
 	}
 
-	shortURL, err := url.JoinPath(urlPath, "api", "v1", "shortUrl", u.GetIDBase62())
+	shortURL, err := model.ShortURL(urlPath, h.strategy.Encode(u.ID))
 
 	if err != nil {
 		return "", fmt.Errorf("url join path err: %w", err)
@@ -197,24 +292,6 @@ func (h *Handler) genTinyURL(u model.URL) (string, error) {
 	return shortURL, nil
 }
 
-func sendBadRequest(w http.ResponseWriter, msg string, logger *zap.SugaredLogger) {
-	res := response{
-		Success: false,
-		Message: msg,
-	}
-	sendJSONResponse(w, http.StatusBadRequest, msg, logger)
-	logger.Debug("response", res)
-}
-
-func sendInternalError(w http.ResponseWriter, msg string, logger *zap.SugaredLogger) {
-	res := response{
-		Success: false,
-		Message: msg,
-	}
-	sendJSONResponse(w, http.StatusInternalServerError, msg, logger)
-	logger.Debug("response", res)
-}
-
 func sendJSONResponse(w http.ResponseWriter, status int, data any, logger *zap.SugaredLogger) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
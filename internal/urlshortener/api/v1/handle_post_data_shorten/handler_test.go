@@ -0,0 +1,100 @@
+package handlepostdatashorten
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/urlshortenertest"
+	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+)
+
+func shorten(t *testing.T, h *Handler, longURL string) string {
+	t.Helper()
+
+	form := url.Values{"long_url": {longURL}}
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/data/shorten", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	var res response
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return res.ShortURL
+}
+
+func TestShortenExistingURLNotAddedToFilter(t *testing.T) {
+	ctx := context.Background()
+	cfg := urlshortenertest.NewConfig(t, map[string]string{"BLOOM_FILTER_TYPE": "cuckoo"})
+
+	filter, err := bloomfilter.NewFromEnv(ctx, cfg.BloomFilterConfig())
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	spy := &urlshortenertest.SpyFilter{Filter: filter}
+	env := urlshortenertest.NewServerEnv(t, cfg, serverenv.WithBloomFilter(spy))
+
+	h, err := New(ctx, cfg, env, urlshortenertest.NewBlocklist(t, cfg, env))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	first := shorten(t, h, "https://example.com/a")
+	adds := spy.Count("Add")
+	if adds == 0 {
+		t.Fatal("new url should be added to filter")
+	}
+
+	if second := shorten(t, h, "https://example.com/a"); second != first {
+		t.Errorf("short url = %q, want %q", second, first)
+	}
+
+	if got := spy.Count("Add"); got != adds {
+		t.Errorf("existing url added to filter again, Add called %d times, want %d", got, adds)
+	}
+}
+
+func TestCreateURLKeyConflictFallback(t *testing.T) {
+	ctx := context.Background()
+	cfg := urlshortenertest.NewConfig(t, nil)
+	env := urlshortenertest.NewServerEnv(t, cfg)
+
+	h, err := New(ctx, cfg, env, urlshortenertest.NewBlocklist(t, cfg, env))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// created before longURL filter existed, filter says it does not exist
+	existing := model.URL{ID: 42, LongURL: "https://example.com/a", CreatedAt: time.Now().UTC()}
+	if err := database.New(env.Database()).CreateURL(ctx, existing); err != nil {
+		t.Fatalf("CreateURL: %v", err)
+	}
+
+	u, created, err := h.createURL(ctx, model.URL{LongURL: existing.LongURL})
+	if err != nil {
+		t.Fatalf("createURL: %v", err)
+	}
+
+	if created {
+		t.Error("existing url should not be reported as created")
+	}
+
+	if u.ID != existing.ID {
+		t.Errorf("ID = %d, want existing %d", u.ID, existing.ID)
+	}
+}
@@ -3,12 +3,29 @@
 package v1
 
 import (
+	"context"
+	"expvar"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
+	"github.com/TinyMurky/tinyurl/internal/middleware"
+	"github.com/TinyMurky/tinyurl/internal/problem"
 	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	handledeleteadminlink "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_delete_admin_link"
+	handlegetadminbloomfilterstats "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_get_admin_bloomfilter_stats"
+	handlegetlink "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_get_link"
+	handlegetlinkdecode "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_get_link_decode"
+	handlegetlinks "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_get_links"
 	handlegetshorturl "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_get_shorturl"
+	handlepostadminbloomfilterrebuild "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_post_admin_bloomfilter_rebuild"
 	handlepostdatashorten "github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/handle_post_data_shorten"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/openapi"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/blocklist"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/clicks"
 	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
 )
 
 // Handler encapsulates the dependencies required for handling V1 version of the URL shortener requests.
@@ -27,17 +44,172 @@ func NewV1Handler(cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) *Han
 	}
 }
 
+// route is an endpoint of v1, every route need an operation in openapi/openapi.json
+type route struct {
+	// pattern of http.ServeMux, relative to /api/v1
+	pattern string
+	// admin route need ADMIN_API_TOKEN
+	admin      bool
+	newHandler newHandlerFunc
+}
+
+// shared is built once by Handler and given to every route,
+// so background jobs of it run once no matter how many handlers use it.
+type shared struct {
+	// blocklist is checked on shorten and again before redirect
+	blocklist *blocklist.Blocklist
+
+	// clicks count redirects, flushed to database in background
+	clicks *clicks.Counter
+}
+
+type newHandlerFunc func(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv, s *shared) (http.Handler, error)
+
+// handlerOf adapt New of handler package into newHandlerFunc
+func handlerOf[H http.Handler](
+	name string,
+	newFn func(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (H, error),
+) newHandlerFunc {
+	return func(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv, _ *shared) (http.Handler, error) {
+		h, err := newFn(ctx, cfg, env)
+		if err != nil {
+			return nil, fmt.Errorf("%s.New: %w", name, err)
+		}
+		return h, nil
+	}
+}
+
+// handlerOfBlocklist adapt New of handler package that need the shared blocklist
+func handlerOfBlocklist[H http.Handler](
+	name string,
+	newFn func(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv, b *blocklist.Blocklist) (H, error),
+) newHandlerFunc {
+	return func(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv, s *shared) (http.Handler, error) {
+		h, err := newFn(ctx, cfg, env, s.blocklist)
+		if err != nil {
+			return nil, fmt.Errorf("%s.New: %w", name, err)
+		}
+		return h, nil
+	}
+}
+
+// newRedirectHandler create handler of redirect, it needs blocklist and clicks
+func newRedirectHandler(
+	ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv, s *shared,
+) (http.Handler, error) {
+	h, err := handlegetshorturl.New(ctx, cfg, env, s.blocklist, s.clicks)
+	if err != nil {
+		return nil, fmt.Errorf("handlegetshorturl.New: %w", err)
+	}
+	return h, nil
+}
+
+// handlerOfStatic use h that has no dependency
+func handlerOfStatic(h http.Handler) newHandlerFunc {
+	return func(context.Context, *urlshortenerconfig.Config, *serverenv.ServerEnv, *shared) (http.Handler, error) {
+		return h, nil
+	}
+}
+
+// routes of v1, test checks every route is in openapi.json
+var routes = []route{
+	{"GET /shortUrl/{id}", false, newRedirectHandler},
+	{"POST /data/shorten", false, handlerOfBlocklist("handlepostdatashorten", handlepostdatashorten.New)},
+	{"GET /links/{id}", false, handlerOf("handlegetlink", handlegetlink.New)},
+	{"GET /openapi.json", false, handlerOfStatic(openapi.Handler())},
+
+	{"POST /admin/bloomfilter/rebuild", true, handlerOf("handlepostadminbloomfilterrebuild", handlepostadminbloomfilterrebuild.New)},
+	{"GET /admin/bloomfilter/stats", true, handlerOf("handlegetadminbloomfilterstats", handlegetadminbloomfilterstats.New)},
+	{"GET /links", true, handlerOf("handlegetlinks", handlegetlinks.New)},
+	// decode tells when and on which node a code was generated
+	{"GET /links/{id}/decode", true, handlerOf("handlegetlinkdecode", handlegetlinkdecode.New)},
+	{"DELETE /admin/links/{id}", true, handlerOf("handledeleteadminlink", handledeleteadminlink.New)},
+	// expvar metrics, ex: clock skew of id generator
+	{"GET /admin/debug/vars", true, handlerOfStatic(expvar.Handler())},
+}
+
 // Handler constructs and returns an http.Handler with all V1 routes registered.
 // This handler serves as the entry point for V1 traffic and can be mounted
 // onto a parent router.
-func (a *Handler) Handler() http.Handler {
+// ctx live as long as the server, background jobs of handlers stop when it is done.
+// Jobs that write state on stop (ex: the last click flush) are added to background,
+// caller waits for it before closing database.
+// Error is returned if any handler can not be created (ex: redis is down).
+//
+// Request bodies are validated against openapi.json before they reach handlers.
+// Unmatched requests get 404 or 405 problem instead of plain text of ServeMux.
+func (a *Handler) Handler(ctx context.Context, background *sync.WaitGroup) (http.Handler, error) {
 	mux := http.NewServeMux()
 
-	getShortURLHandler := handlegetshorturl.New(a.config, a.env)
-	postDataShortenHandler := handlepostdatashorten.New(a.config, a.env)
+	spec, err := openapi.Load()
+	if err != nil {
+		return nil, fmt.Errorf("openapi.Load: %w", err)
+	}
+
+	requireAdmin := middleware.RequireBearerToken(a.config.AdminAPIToken)
+
+	blocklist, err := blocklist.New(ctx, a.env.Cache(), a.env.RedisCircuitBreaker(), a.config)
+	if err != nil {
+		return nil, fmt.Errorf("blocklist.New: %w", err)
+	}
+	go blocklist.Run(ctx)
+
+	clicks := clicks.New(database.New(a.env.Database()))
+	background.Go(func() { clicks.Run(ctx, a.config.ClickFlushInterval) })
+
+	s := &shared{
+		blocklist: blocklist,
+		clicks:    clicks,
+	}
+
+	for _, rt := range routes {
+		h, err := rt.newHandler(ctx, a.config, a.env, s)
+		if err != nil {
+			return nil, err
+		}
+
+		h = spec.ValidateRequest(rt.pattern)(h)
+
+		if rt.admin {
+			h = requireAdmin(h)
+		}
+
+		mux.Handle(rt.pattern, h)
+	}
+
+	mux.Handle("/", unmatched(mux))
+
+	return mux, nil
+}
+
+// methods are tried by unmatched to find Allow of path
+var methods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// unmatched handle request that only matches catch-all "/" of mux.
+// It responds 405 if path is registered with other methods, otherwise 404.
+func unmatched(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allow []string
+		for _, method := range methods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+
+			if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/" {
+				allow = append(allow, method)
+			}
+		}
 
-	mux.Handle("GET /shortUrl/{id}", getShortURLHandler)
-	mux.Handle("POST /data/shorten", postDataShortenHandler)
+		if len(allow) == 0 {
+			problem.NotFound(w, r)
+			return
+		}
 
-	return mux
+		problem.MethodNotAllowed(w, r, strings.Join(allow, ", "))
+	})
 }
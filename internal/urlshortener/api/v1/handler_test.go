@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/problem"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/api/v1/openapi"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	idgenerator "github.com/TinyMurky/tinyurl/internal/urlshortener/id_generator"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/urlshortenertest"
+)
+
+func TestRoutesInOpenAPI(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("openapi.Load: %v", err)
+	}
+
+	var patterns []string
+	for _, rt := range routes {
+		patterns = append(patterns, rt.pattern)
+
+		if _, ok := spec.Operation(rt.pattern); !ok {
+			t.Errorf("route %q is not in openapi.json", rt.pattern)
+		}
+	}
+
+	for _, pattern := range spec.Patterns() {
+		if !slices.Contains(patterns, pattern) {
+			t.Errorf("openapi.json has %q but it is not registered", pattern)
+		}
+	}
+}
+
+func TestUnmatched(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	mux.Handle("GET /links/{id}/decode", ok)
+	mux.Handle("DELETE /links/{id}/decode", ok)
+	mux.Handle("/", unmatched(mux))
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		allow  string
+	}{
+		{http.MethodGet, "/links/abc/decode", http.StatusOK, ""},
+		{http.MethodPost, "/links/abc/decode", http.StatusMethodNotAllowed, "GET, DELETE"},
+		{http.MethodGet, "/nope", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, w.Code, tt.status)
+		}
+
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: Allow = %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+
+		if tt.status != http.StatusOK && w.Header().Get("Content-Type") != problem.ContentType {
+			t.Errorf("%s %s: Content-Type = %q", tt.method, tt.path, w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestClicksFlushedBeforeBackgroundDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// no periodic flush, clicks are written only on stop
+	cfg := urlshortenertest.NewConfig(t, map[string]string{"CLICK_FLUSH_INTERVAL": "0"})
+	env := urlshortenertest.NewServerEnv(t, cfg)
+	db := database.New(env.Database())
+
+	u := model.URL{ID: 123456789, LongURL: "https://example.com/a", CreatedAt: time.Now().UTC()}
+	if err := db.CreateURL(ctx, u); err != nil {
+		t.Fatalf("CreateURL: %v", err)
+	}
+
+	bf, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		t.Fatalf("bloomfilter.New: %v", err)
+	}
+	if err := bf.AddURL(ctx, u); err != nil {
+		t.Fatalf("AddURL: %v", err)
+	}
+
+	codec, err := idgenerator.NewCodec(cfg)
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+
+	var background sync.WaitGroup
+	h, err := NewV1Handler(cfg, env).Handler(ctx, &background)
+	if err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shortUrl/"+codec.Encode(u.ID), nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	cancel()
+	background.Wait()
+
+	count, err := db.GetClickCount(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("GetClickCount: %v", err)
+	}
+
+	if count != 1 {
+		t.Errorf("click count = %d after background is done, want 1", count)
+	}
+}
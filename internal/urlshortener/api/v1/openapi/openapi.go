@@ -0,0 +1,113 @@
+// Package openapi serve the OpenAPI 3.1 document of /api/v1,
+// and validate request bodies against its schemas.
+//
+// Every route registered in v1.Handler must have an operation in openapi.json,
+// it is checked by test of package v1.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//go:embed openapi.json
+var document []byte
+
+// Spec is the part of OpenAPI document used by routing check and request validation
+type Spec struct {
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Operation is an operation of a path, ex: "get"
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	RequestBody *RequestBody `json:"requestBody"`
+}
+
+// RequestBody describe body of request by content type
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// MediaType is schema of one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema that request validation supports
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Required   []string           `json:"required"`
+	Properties map[string]*Schema `json:"properties"`
+	Items      *Schema            `json:"items"`
+	Enum       []any              `json:"enum"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+}
+
+// Load parse the embedded document
+func Load() (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(document, &spec); err != nil {
+		return nil, fmt.Errorf("parse openapi.json: %w", err)
+	}
+	return &spec, nil
+}
+
+// Operation return operation of ServeMux pattern, ex: "GET /shortUrl/{id}".
+// Path of pattern is relative to /api/v1, same as paths of document.
+func (s *Spec) Operation(pattern string) (*Operation, bool) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		return nil, false
+	}
+
+	op, ok := s.Paths[path][strings.ToLower(method)]
+	return op, ok && op != nil
+}
+
+// Patterns return ServeMux pattern of every operation in document
+func (s *Spec) Patterns() []string {
+	var patterns []string
+	for path, ops := range s.Paths {
+		for method := range ops {
+			patterns = append(patterns, strings.ToUpper(method)+" "+path)
+		}
+	}
+	return patterns
+}
+
+// resolve follow $ref of schema in components
+func (s *Spec) resolve(schema *Schema) (*Schema, error) {
+	for schema != nil && schema.Ref != "" {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if !ok {
+			return nil, fmt.Errorf("unsupported $ref %q", schema.Ref)
+		}
+
+		next, ok := s.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("$ref %q not found", schema.Ref)
+		}
+		schema = next
+	}
+	return schema, nil
+}
+
+// Handler serve the document as JSON
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(document)
+	})
+}
@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TinyMurky/tinyurl/internal/middleware"
+	"github.com/TinyMurky/tinyurl/internal/problem"
+)
+
+// maxBodyBytes is the largest request body read for validation
+const maxBodyBytes = 1 << 20
+
+const (
+	contentTypeForm = "application/x-www-form-urlencoded"
+	contentTypeJSON = "application/json"
+)
+
+// ValidateRequest return middleware that validate request body of pattern
+// against schema of its operation, invalid request gets 400 problem.
+// Request of operation without request body is passed through.
+//
+// Form values are validated as properties of the object schema,
+// JSON body is validated as a whole and can be read again by handler.
+func (s *Spec) ValidateRequest(pattern string) middleware.Middleware {
+	op, ok := s.Operation(pattern)
+	if !ok || op.RequestBody == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	body := op.RequestBody
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// errors of validateBody describe the request only, safe to show to clients
+			if err := s.validateBody(w, r, body); err != nil {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *Spec) validateBody(w http.ResponseWriter, r *http.Request, body *RequestBody) error {
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil && r.ContentLength == 0 && !body.Required {
+		return nil
+	}
+
+	media, ok := body.Content[contentType]
+	if err != nil || !ok {
+		return fmt.Errorf("Content-Type need to be %s", strings.Join(slices.Sorted(maps.Keys(body.Content)), " or "))
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+	switch contentType {
+	case contentTypeForm:
+		if err := r.ParseForm(); err != nil {
+			return errors.New("failed to parse form")
+		}
+		return s.validateForm(media.Schema, r.PostForm)
+
+	case contentTypeJSON:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read body: %w", err)
+		}
+		// handler read body again
+		r.Body = io.NopCloser(bytes.NewReader(data))
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var v any
+		if err := decoder.Decode(&v); err != nil {
+			return fmt.Errorf("invalid JSON body: %w", err)
+		}
+		return s.validate(media.Schema, v, "body")
+
+	default:
+		// schema of other content types is documentation only
+		return nil
+	}
+}
+
+// validateForm validate form values as properties of object schema,
+// empty value is treated as missing.
+func (s *Spec) validateForm(schema *Schema, values url.Values) error {
+	schema, err := s.resolve(schema)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	for _, name := range schema.Required {
+		if values.Get(name) == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+	}
+
+	for name, prop := range schema.Properties {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+
+		prop, err := s.resolve(prop)
+		if err != nil {
+			return err
+		}
+
+		if err := s.validate(prop, formValue(prop, raw), name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formValue convert form value into the type of schema, so validate can check it
+func formValue(schema *Schema, raw string) any {
+	switch schema.Type {
+	case "integer", "number":
+		return json.Number(raw)
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// validate check value decoded from JSON (with UseNumber) against schema
+func (s *Spec) validate(schema *Schema, v any, path string) error {
+	schema, err := s.resolve(schema)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool {
+		return fmt.Sprint(e) == fmt.Sprint(v)
+	}) {
+		return fmt.Errorf("%s must be one of %v", path, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s must be object", path)
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s.%s is required", path, name)
+			}
+		}
+
+		for name, prop := range schema.Properties {
+			if value, ok := obj[name]; ok {
+				if err := s.validate(prop, value, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s must be array", path)
+		}
+
+		for i, item := range items {
+			if err := s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s must be string", path)
+		}
+		return validateString(schema, str, path)
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if _, err := n.Int64(); !ok || err != nil {
+			return fmt.Errorf("%s must be integer", path)
+		}
+
+	case "number":
+		n, ok := v.(json.Number)
+		if _, err := n.Float64(); !ok || err != nil {
+			return fmt.Errorf("%s must be number", path)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s must be boolean", path)
+		}
+
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", path, schema.Type)
+	}
+
+	return nil
+}
+
+func validateString(schema *Schema, str string, path string) error {
+	length := utf8.RuneCountInString(str)
+
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("%s must be at least %d characters", path, *schema.MinLength)
+	}
+
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("%s must be at most %d characters", path, *schema.MaxLength)
+	}
+
+	// same check as shorten handler, url need to start with scheme
+	if schema.Format == "uri" {
+		if _, err := url.ParseRequestURI(str); err != nil {
+			return fmt.Errorf("%s %q is invalid uri", path, str)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateRequest(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var reached bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("handler ParseForm: %v", err)
+		}
+	})
+	h := spec.ValidateRequest("POST /data/shorten")(next)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"valid", contentTypeForm, "long_url=https%3A%2F%2Fexample.com", http.StatusOK},
+		{"missing", contentTypeForm, "other=1", http.StatusBadRequest},
+		{"empty", contentTypeForm, "long_url=", http.StatusBadRequest},
+		{"not uri", contentTypeForm, "long_url=example", http.StatusBadRequest},
+		{"wrong content type", contentTypeJSON, `{"long_url":"https://example.com"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		reached = false
+
+		r := httptest.NewRequest(http.MethodPost, "/data/shorten", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d, body %s", tt.name, w.Code, tt.status, w.Body.String())
+		}
+
+		if reached != (tt.status == http.StatusOK) {
+			t.Errorf("%s: handler reached = %v", tt.name, reached)
+		}
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	minLength := 3
+	spec := &Spec{}
+	spec.Components.Schemas = map[string]*Schema{
+		"Item": {
+			Type:     "object",
+			Required: []string{"name"},
+			Properties: map[string]*Schema{
+				"name":  {Type: "string", MinLength: &minLength},
+				"count": {Type: "integer"},
+				"tags":  {Type: "array", Items: &Schema{Type: "string"}},
+			},
+		},
+	}
+	spec.Paths = map[string]map[string]*Operation{
+		"/items": {
+			"post": {RequestBody: &RequestBody{
+				Required: true,
+				Content: map[string]*MediaType{
+					contentTypeJSON: {Schema: &Schema{Ref: "#/components/schemas/Item"}},
+				},
+			}},
+		},
+	}
+
+	h := spec.ValidateRequest("POST /items")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// body can be read again
+		if data, _ := io.ReadAll(r.Body); len(data) == 0 {
+			t.Error("handler got empty body")
+		}
+	}))
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"name":"abc","count":2,"tags":["a"]}`, http.StatusOK},
+		{`{"name":"ab"}`, http.StatusBadRequest},
+		{`{"count":2}`, http.StatusBadRequest},
+		{`{"name":"abc","count":1.5}`, http.StatusBadRequest},
+		{`{"name":"abc","tags":[1]}`, http.StatusBadRequest},
+		{`[]`, http.StatusBadRequest},
+		{`{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d, body %s", tt.body, w.Code, tt.status, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package blocklist refuse long urls whose destination is blocked.
+//
+// Rules come from a file and from a key in cache (redis),
+// both are reloaded periodically so rules change without restart.
+package blocklist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	pkgcache "github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// Blocklist hold rules of file and redis,
+// rules of a source are kept if it fails to reload.
+type Blocklist struct {
+	cfg          urlshortenerconfig.BlocklistConfig
+	cache        pkgcache.Cache
+	redisBreaker *circuitbreaker.Breaker
+
+	fileRules  atomic.Pointer[Rules]
+	redisRules atomic.Pointer[Rules]
+}
+
+// New create Blocklist and load rules once.
+// Invalid file is an error, because server should not start without the rules it is told to use,
+// redis failure is only logged and retried on next reload.
+func New(
+	ctx context.Context, cache pkgcache.Cache, redisBreaker *circuitbreaker.Breaker, cfg *urlshortenerconfig.Config,
+) (*Blocklist, error) {
+	b := &Blocklist{
+		cfg:          cfg.Blocklist,
+		cache:        cache,
+		redisBreaker: redisBreaker,
+	}
+
+	if err := b.reloadFile(); err != nil {
+		return nil, err
+	}
+
+	if err := b.reloadRedis(ctx); err != nil {
+		logging.FromContext(ctx).Named("blocklist").Warnf("load blocklist from redis: %s", err.Error())
+	}
+
+	return b, nil
+}
+
+// IsBlocked return true if longURL is blocked by rules of any source
+func (b *Blocklist) IsBlocked(longURL string) bool {
+	return b.fileRules.Load().Match(longURL) || b.redisRules.Load().Match(longURL)
+}
+
+// Reload load rules of every source again, sources that failed keep their old rules.
+func (b *Blocklist) Reload(ctx context.Context) error {
+	return errors.Join(b.reloadFile(), b.reloadRedis(ctx))
+}
+
+// Run reload rules every ReloadInterval until ctx is done.
+func (b *Blocklist) Run(ctx context.Context) {
+	logger := logging.FromContext(ctx).Named("blocklist")
+
+	if b.cfg.ReloadInterval <= 0 {
+		logger.Info("blocklist reload disabled")
+		return
+	}
+
+	ticker := time.NewTicker(b.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.Reload(ctx); err != nil {
+				logger.Warnf("reload blocklist: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (b *Blocklist) reloadFile() error {
+	if b.cfg.File == "" {
+		return nil
+	}
+
+	text, err := os.ReadFile(b.cfg.File)
+	if err != nil {
+		return fmt.Errorf("read blocklist file: %w", err)
+	}
+
+	rules, err := ParseRules(string(text))
+	if err != nil {
+		return fmt.Errorf("parse blocklist file %q: %w", b.cfg.File, err)
+	}
+
+	b.fileRules.Store(rules)
+	return nil
+}
+
+// reloadRedis load rules through circuit breaker, key not exist means no rules.
+func (b *Blocklist) reloadRedis(ctx context.Context) error {
+	if b.cfg.RedisKey == "" {
+		return nil
+	}
+
+	var text string
+
+	err := b.redisBreaker.Do(func() error {
+		var err error
+		text, err = b.cache.Get(ctx, b.cfg.RedisKey)
+		if errors.Is(err, pkgcache.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("get blocklist from redis: %w", err)
+	}
+
+	rules, err := ParseRules(text)
+	if err != nil {
+		return fmt.Errorf("parse blocklist of redis key %q: %w", b.cfg.RedisKey, err)
+	}
+
+	b.redisRules.Store(rules)
+	return nil
+}
@@ -0,0 +1,54 @@
+package blocklist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	pkgcache "github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+)
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(file, []byte("bad.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := pkgcache.NewMemoryCache(10)
+	cfg := &urlshortenerconfig.Config{
+		Blocklist: urlshortenerconfig.BlocklistConfig{
+			File:     file,
+			RedisKey: "urlshortener:blocklist",
+		},
+	}
+
+	b, err := New(ctx, cache, circuitbreaker.New(ctx, "redis", &circuitbreaker.Config{}), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if !b.IsBlocked("https://bad.com") || b.IsBlocked("https://evil.org") {
+		t.Fatal("only file rule should be loaded")
+	}
+
+	if err := cache.Set(ctx, "urlshortener:blocklist", "evil.org", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid file keep the old file rules
+	if err := os.WriteFile(file, []byte("regex:("), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Reload(ctx); err == nil {
+		t.Error("Reload should report invalid file")
+	}
+
+	if !b.IsBlocked("https://bad.com") || !b.IsBlocked("https://evil.org") {
+		t.Error("file rule should be kept and redis rule should be loaded")
+	}
+}
@@ -0,0 +1,113 @@
+package blocklist
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// regexRulePrefix mark a rule as regular expression
+const regexRulePrefix = "regex:"
+
+// Rules is a parsed blocklist, zero value blocks nothing.
+//
+// Each line of blocklist is one of:
+//
+//	example.com            exact host
+//	*.example.com          any subdomain of example.com (not example.com itself)
+//	regex:^example\.com/a/ regular expression on host + path
+//
+// Empty lines and lines start with "#" are ignored.
+type Rules struct {
+	hosts    map[string]struct{}
+	suffixes []string
+	regexps  []*regexp.Regexp
+}
+
+// ParseRules parse blocklist text, error tells the line of invalid rule
+func ParseRules(text string) (*Rules, error) {
+	rules := &Rules{
+		hosts: make(map[string]struct{}),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		rule := strings.TrimSpace(scanner.Text())
+
+		if rule == "" || strings.HasPrefix(rule, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(rule, regexRulePrefix):
+			re, err := regexp.Compile(strings.TrimPrefix(rule, regexRulePrefix))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			rules.regexps = append(rules.regexps, re)
+
+		case strings.HasPrefix(rule, "*."):
+			// keep the leading "." so "badexample.com" is not matched by "*.example.com"
+			rules.suffixes = append(rules.suffixes, strings.ToLower(rule[1:]))
+
+		default:
+			if strings.ContainsAny(rule, "*/ \t") {
+				return nil, fmt.Errorf("line %d: invalid host %q", line, rule)
+			}
+			rules.hosts[strings.ToLower(rule)] = struct{}{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Len return number of rules
+func (r *Rules) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.hosts) + len(r.suffixes) + len(r.regexps)
+}
+
+// Match return true if longURL is blocked by any rule.
+// longURL that can not be parsed is not matched, validation is done by caller.
+func (r *Rules) Match(longURL string) bool {
+	if r.Len() == 0 {
+		return false
+	}
+
+	u, err := url.Parse(longURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	if _, ok := r.hosts[host]; ok {
+		return true
+	}
+
+	for _, suffix := range r.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	hostPath := host + u.Path
+	for _, re := range r.regexps {
+		if re.MatchString(hostPath) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package blocklist
+
+import "testing"
+
+func TestRulesMatch(t *testing.T) {
+	rules, err := ParseRules(`
+# comment
+bad.com
+*.evil.org
+regex:^example\.com/phish/
+`)
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+
+	tests := []struct {
+		longURL string
+		want    bool
+	}{
+		{"https://bad.com/a", true},
+		{"https://BAD.com:8080", true},
+		{"https://sub.bad.com", false},
+		{"https://a.evil.org/x", true},
+		{"https://a.b.evil.org", true},
+		{"https://evil.org", false},
+		{"https://notevil.org", false},
+		{"https://example.com/phish/1", true},
+		{"https://example.com/safe", false},
+	}
+
+	for _, tt := range tests {
+		if got := rules.Match(tt.longURL); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.longURL, got, tt.want)
+		}
+	}
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	for _, text := range []string{"regex:(", "bad.com/path", "evil*.org"} {
+		if _, err := ParseRules(text); err == nil {
+			t.Errorf("ParseRules(%q) should fail", text)
+		}
+	}
+}
@@ -3,79 +3,153 @@ package bloomfilter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
 	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
 )
 
+// ErrRemoveNotSupported is returned when item is removed from bloom filter,
+// BLOOM_FILTER_TYPE need to be cuckoo to remove item.
+var ErrRemoveNotSupported = errors.New("filter does not support remove")
+
 // URLShortenerBloomFilter is to create bloom filter for url shortener
 type URLShortenerBloomFilter struct {
-	filter *bloomfilter.BloomFilter
+	filter bloomfilter.Filter
 	cfg    *bloomfilter.Config
 }
 
-// New URLShortenerBloomFilter
-func New(filter *bloomfilter.BloomFilter, cfg *bloomfilter.Config) *URLShortenerBloomFilter {
+// New URLShortenerBloomFilter, base62 ID and longURL filters are reserved
+// if they are not exist.
+func New(ctx context.Context, filter bloomfilter.Filter, cfg *bloomfilter.Config) (*URLShortenerBloomFilter, error) {
 	bf := &URLShortenerBloomFilter{
 		filter: filter,
 		cfg:    cfg,
 	}
 
-	if err := bf.reserveBase62ID(context.Background()); err != nil {
-		panic(fmt.Errorf("reserveBase62ID: %w", err))
+	if err := bf.reserveBase62ID(ctx); err != nil {
+		return nil, fmt.Errorf("reserveBase62ID: %w", err)
+	}
+
+	if err := bf.reserveLongURL(ctx); err != nil {
+		return nil, fmt.Errorf("reserveLongURL: %w", err)
 	}
 
-	return bf
+	return bf, nil
 }
 
 func (bf *URLShortenerBloomFilter) reserveBase62ID(ctx context.Context) error {
 	key := genBase62IDKey()
 	errorRate := bf.cfg.RedisBloomFilterErrorRate
 	capacity := bf.cfg.RedisBloomFilterCapacity
-	_, err := bf.reserve(ctx, key, errorRate, capacity)
-	return err
+	return bf.filter.Reserve(ctx, key, errorRate, capacity)
+}
+
+func (bf *URLShortenerBloomFilter) reserveLongURL(ctx context.Context) error {
+	key := genLongURLKey()
+	errorRate := bf.cfg.RedisBloomFilterErrorRate
+	capacity := bf.cfg.RedisBloomFilterCapacity
+	return bf.filter.Reserve(ctx, key, errorRate, capacity)
+}
+
+// AddURL add both base62 ID and longURL of url to bloom filter
+func (bf *URLShortenerBloomFilter) AddURL(ctx context.Context, u model.URL) error {
+	if err := bf.AddURLBase62ID(ctx, u); err != nil {
+		return err
+	}
+
+	return bf.AddLongURL(ctx, u)
 }
 
 // AddURLBase62ID add base62 ID of url to bloom filter
 func (bf *URLShortenerBloomFilter) AddURLBase62ID(ctx context.Context, u model.URL) error {
 	key := genBase62IDKey()
-	return bf.add(ctx, key, u.GetIDBase62())
+	return bf.filter.Add(ctx, key, genBase62IDItem(u))
 }
 
 // IsURLBase62IDExist check if base62 ID in bloom filter
 func (bf *URLShortenerBloomFilter) IsURLBase62IDExist(ctx context.Context, u model.URL) (bool, error) {
 	key := genBase62IDKey()
-	return bf.exists(ctx, key, u.GetIDBase62())
+	return bf.filter.Exists(ctx, key, genBase62IDItem(u))
 }
 
-// https://redis.io/docs/latest/commands/bf.reserve/
-func (bf *URLShortenerBloomFilter) reserve(
-	ctx context.Context, key string, errorRate float64, capacity int64,
-) (bool, error) {
-	err := bf.filter.RDB.BFReserve(ctx, key, errorRate, capacity).Err()
-
-	if err != nil && err.Error() != "ERR item exists" {
-		return false, err
+// AddLongURL add longURL of url to bloom filter
+func (bf *URLShortenerBloomFilter) AddLongURL(ctx context.Context, u model.URL) error {
+	if u.IsEmptyLongURL() {
+		return errors.New("AddLongURL: longURL is empty")
 	}
 
-	if err != nil {
-		return false, nil
+	key := genLongURLKey()
+	return bf.filter.Add(ctx, key, genLongURLItem(u.LongURL))
+}
+
+// IsLongURLExist check if longURL might be in database.
+//
+// The filter is filled on write path only, url created before it existed
+// is reported as not exist. Insert of such url hits the unique constraint
+// of database and caller need to fall back to query.
+func (bf *URLShortenerBloomFilter) IsLongURLExist(ctx context.Context, u model.URL) (bool, error) {
+	key := genLongURLKey()
+	return bf.filter.Exists(ctx, key, genLongURLItem(u.LongURL))
+}
+
+// CanRemove report whether base62 ID can be removed (cuckoo filter)
+func (bf *URLShortenerBloomFilter) CanRemove() bool {
+	_, ok := bf.filter.(bloomfilter.Remover)
+	return ok
+}
+
+// RemoveURLBase62ID remove base62 ID of deleted url from filter,
+// so that it is answered by filter instead of database.
+// ErrRemoveNotSupported is returned if filter is bloom filter.
+func (bf *URLShortenerBloomFilter) RemoveURLBase62ID(ctx context.Context, u model.URL) error {
+	remover, ok := bf.filter.(bloomfilter.Remover)
+	if !ok {
+		return ErrRemoveNotSupported
 	}
 
-	return true, nil
+	key := genBase62IDKey()
+	return remover.Remove(ctx, key, genBase62IDItem(u))
+}
+
+// FilterStats is the usage of a filter
+type FilterStats struct {
+	Capacity                   int64   `json:"capacity"`
+	Items                      int64   `json:"items"`
+	Filters                    int64   `json:"filters"`
+	FillRatio                  float64 `json:"fill_ratio"`
+	EstimatedFalsePositiveRate float64 `json:"estimated_false_positive_rate"`
 }
 
-// https://redis.io/docs/latest/commands/bf.add/
-func (bf *URLShortenerBloomFilter) add(
-	ctx context.Context, key string, element any,
-) error {
-	return bf.filter.RDB.BFAdd(ctx, key, element).Err()
+// Base62IDStats read usage of base62 ID filter and estimate its false positive rate
+func (bf *URLShortenerBloomFilter) Base62IDStats(ctx context.Context) (FilterStats, error) {
+	return bf.stats(ctx, genBase62IDKey())
 }
 
-// https://redis.io/docs/latest/commands/bf.exists/
-func (bf *URLShortenerBloomFilter) exists(
-	ctx context.Context, key string, element any,
-) (bool, error) {
-	return bf.filter.RDB.BFExists(ctx, key, element).Result()
+// LongURLStats read usage of longURL filter and estimate its false positive rate
+func (bf *URLShortenerBloomFilter) LongURLStats(ctx context.Context) (FilterStats, error) {
+	return bf.stats(ctx, genLongURLKey())
+}
+
+func (bf *URLShortenerBloomFilter) stats(ctx context.Context, key string) (FilterStats, error) {
+	info, err := bf.filter.Info(ctx, key)
+	if err != nil {
+		return FilterStats{}, fmt.Errorf("info of %s: %w", key, err)
+	}
+
+	estimatedFalsePositiveRate := bloomfilter.EstimateFalsePositiveRate(
+		bf.cfg.RedisBloomFilterErrorRate, info.Capacity, info.Items,
+	)
+	if bf.cfg.FilterType() == bloomfilter.TypeCuckoo {
+		estimatedFalsePositiveRate = bloomfilter.EstimateCuckooFalsePositiveRate(info.Capacity, info.Items)
+	}
+
+	return FilterStats{
+		Capacity:                   info.Capacity,
+		Items:                      info.Items,
+		Filters:                    info.Filters,
+		FillRatio:                  info.FillRatio(),
+		EstimatedFalsePositiveRate: estimatedFalsePositiveRate,
+	}, nil
 }
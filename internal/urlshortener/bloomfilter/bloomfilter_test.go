@@ -0,0 +1,86 @@
+package bloomfilter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+)
+
+func TestRemoveURLBase62ID(t *testing.T) {
+	ctx := context.Background()
+	u := model.URL{ID: 12345}
+
+	cfg := &bloomfilter.Config{
+		Type:                      bloomfilter.TypeCuckoo,
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  10,
+	}
+	bf, err := New(ctx, bloomfilter.NewMemoryCuckooFilter(cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := bf.AddURLBase62ID(ctx, u); err != nil {
+		t.Fatalf("AddURLBase62ID: %v", err)
+	}
+
+	if err := bf.RemoveURLBase62ID(ctx, u); err != nil {
+		t.Fatalf("RemoveURLBase62ID: %v", err)
+	}
+
+	if ok, _ := bf.IsURLBase62IDExist(ctx, u); ok {
+		t.Error("removed id should not exist")
+	}
+
+	// bloom filter can not remove
+	cfg = &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  10,
+	}
+	bf, err = New(ctx, bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := bf.RemoveURLBase62ID(ctx, u); !errors.Is(err, ErrRemoveNotSupported) {
+		t.Errorf("expect ErrRemoveNotSupported, got %v", err)
+	}
+}
+
+func TestLongURL(t *testing.T) {
+	ctx := context.Background()
+	cfg := &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  10,
+	}
+	bf, err := New(ctx, bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	u := model.URL{ID: 12345, LongURL: "https://example.com/a"}
+
+	if ok, _ := bf.IsLongURLExist(ctx, u); ok {
+		t.Fatal("longURL should not exist before add")
+	}
+
+	if err := bf.AddURL(ctx, u); err != nil {
+		t.Fatalf("AddURL: %v", err)
+	}
+
+	if ok, _ := bf.IsLongURLExist(ctx, u); !ok {
+		t.Error("longURL should exist after AddURL")
+	}
+
+	if ok, _ := bf.IsURLBase62IDExist(ctx, u); !ok {
+		t.Error("base62 ID should exist after AddURL")
+	}
+
+	// base62 ID and longURL are in different filter
+	if ok, _ := bf.IsLongURLExist(ctx, model.URL{LongURL: u.GetIDBase62()}); ok {
+		t.Error("base62 ID should not be in longURL filter")
+	}
+}
@@ -0,0 +1,190 @@
+package bloomfilter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// probeIDGap is added to the largest id in database to get the smallest
+// probe id, so probe ids are in the future and never issued.
+// It is one year of snowflake timestamp (timestamp is above the 22 bits of node and step).
+const probeIDGap = snowflake.SID(365 * 24 * time.Hour / time.Millisecond << 22)
+
+// URLIDSource provide ids of all urls, it is implemented by database.URLShortenerDB
+type URLIDSource interface {
+	ListIDsAfter(ctx context.Context, afterID snowflake.SID, limit int) ([]snowflake.SID, error)
+}
+
+// CheckOptions control CheckBase62ID
+type CheckOptions struct {
+	// BatchSize is the number of ids read from database at once
+	BatchSize int
+	// SampleRate is the fraction (0, 1] of ids checked against filter,
+	// all ids are checked if it is out of range.
+	SampleRate float64
+	// FalsePositiveProbes is the number of never-issued ids probed
+	// to observe false positive rate
+	FalsePositiveProbes int
+	// Repair add missing ids back to filter
+	Repair bool
+	// MaxReportedMissing limit the number of missing ids kept in result
+	MaxReportedMissing int
+}
+
+// CheckResult is the report of CheckBase62ID
+type CheckResult struct {
+	Scanned    int64    `json:"scanned"`
+	Checked    int64    `json:"checked"`
+	Missing    int64    `json:"missing"`
+	MissingIDs []string `json:"missing_ids,omitempty"`
+	Repaired   int64    `json:"repaired"`
+
+	Probes                    int64   `json:"probes"`
+	FalsePositives            int64   `json:"false_positives"`
+	ObservedFalsePositiveRate float64 `json:"observed_false_positive_rate"`
+
+	Duration time.Duration `json:"duration"`
+}
+
+// CheckBase62ID verify base62 ID filter covers every url in source.
+//
+// Ids are streamed from source and (a sample of them) checked with filter,
+// any "not exist" answer is a missing member that makes the link 404.
+// Then random ids larger than any id in source are probed,
+// any "might exist" answer of them is a false positive.
+func (bf *URLShortenerBloomFilter) CheckBase62ID(
+	ctx context.Context, source URLIDSource, opts CheckOptions,
+) (CheckResult, error) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_check")
+	start := time.Now()
+
+	if opts.BatchSize <= 0 {
+		return CheckResult{}, fmt.Errorf("batch size must be positive, got %d", opts.BatchSize)
+	}
+
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		opts.SampleRate = 1
+	}
+
+	key := genBase62IDKey()
+
+	var result CheckResult
+	var lastID snowflake.SID
+
+	for {
+		ids, err := source.ListIDsAfter(ctx, lastID, opts.BatchSize)
+		if err != nil {
+			return result, fmt.Errorf("list ids after %d: %w", lastID, err)
+		}
+
+		if len(ids) == 0 {
+			break
+		}
+
+		var items []string
+		for _, id := range ids {
+			if opts.SampleRate < 1 && rand.Float64() >= opts.SampleRate {
+				continue
+			}
+
+			u := model.URL{ID: id}
+			items = append(items, genBase62IDItem(u))
+		}
+
+		exists, err := bf.filter.ExistsMany(ctx, key, items)
+		if err != nil {
+			return result, fmt.Errorf("check %d ids after %d: %w", len(items), lastID, err)
+		}
+
+		var missing []string
+		for i, exist := range exists {
+			if !exist {
+				missing = append(missing, items[i])
+			}
+		}
+
+		result.Checked += int64(len(items))
+
+		result.Scanned += int64(len(ids))
+		result.Missing += int64(len(missing))
+		lastID = ids[len(ids)-1]
+
+		for _, id := range missing {
+			if len(result.MissingIDs) >= opts.MaxReportedMissing {
+				break
+			}
+			result.MissingIDs = append(result.MissingIDs, id)
+		}
+
+		if opts.Repair && len(missing) > 0 {
+			if err := bf.filter.AddMany(ctx, key, missing); err != nil {
+				return result, fmt.Errorf("repair %d ids: %w", len(missing), err)
+			}
+			result.Repaired += int64(len(missing))
+		}
+	}
+
+	if err := bf.probeFalsePositive(ctx, lastID, opts.FalsePositiveProbes, &result); err != nil {
+		return result, err
+	}
+
+	result.Duration = time.Since(start)
+
+	logger.Infow("bloom filter check finished",
+		"key", key,
+		"scanned", result.Scanned,
+		"checked", result.Checked,
+		"missing", result.Missing,
+		"repaired", result.Repaired,
+		"probes", result.Probes,
+		"observed_false_positive_rate", result.ObservedFalsePositiveRate,
+		"duration", result.Duration.String(),
+	)
+
+	return result, nil
+}
+
+// probeFalsePositive probe random ids after maxID + probeIDGap
+func (bf *URLShortenerBloomFilter) probeFalsePositive(
+	ctx context.Context, maxID snowflake.SID, probes int, result *CheckResult,
+) error {
+	if probes <= 0 {
+		return nil
+	}
+
+	floor := maxID + probeIDGap
+	if floor < maxID || floor == math.MaxInt64 {
+		return fmt.Errorf("no never-issued id after %s to probe", maxID.Base62())
+	}
+	span := int64(math.MaxInt64 - floor)
+
+	items := make([]string, probes)
+	for i := range items {
+		u := model.URL{ID: floor + snowflake.SID(rand.Int64N(span))}
+		items[i] = genBase62IDItem(u)
+	}
+
+	exists, err := bf.filter.ExistsMany(ctx, genBase62IDKey(), items)
+	if err != nil {
+		return fmt.Errorf("probe %d ids: %w", len(items), err)
+	}
+
+	for _, exist := range exists {
+		result.Probes++
+		if exist {
+			result.FalsePositives++
+		}
+	}
+
+	result.ObservedFalsePositiveRate = float64(result.FalsePositives) / float64(result.Probes)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package bloomfilter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+)
+
+func TestCheckBase62ID(t *testing.T) {
+	ctx := context.Background()
+	cfg := &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  100,
+	}
+
+	bf, err := New(ctx, bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	source := &fakeSource{}
+	for i := 1; i <= 25; i++ {
+		id := snowflake.SID(i * 1000)
+		source.ids = append(source.ids, id)
+
+		// every 5th id is missing from filter
+		if i%5 == 0 {
+			continue
+		}
+		if err := bf.AddURLBase62ID(ctx, model.URL{ID: id}); err != nil {
+			t.Fatalf("AddURLBase62ID: %v", err)
+		}
+	}
+
+	opts := CheckOptions{
+		BatchSize:           7,
+		FalsePositiveProbes: 100,
+		MaxReportedMissing:  3,
+	}
+
+	result, err := bf.CheckBase62ID(ctx, source, opts)
+	if err != nil {
+		t.Fatalf("CheckBase62ID: %v", err)
+	}
+
+	if result.Scanned != 25 || result.Checked != 25 {
+		t.Errorf("expect 25 scanned and checked, got %d and %d", result.Scanned, result.Checked)
+	}
+
+	if result.Missing != 5 || len(result.MissingIDs) != 3 || result.Repaired != 0 {
+		t.Errorf("expect 5 missing, 3 reported and 0 repaired, got %+v", result)
+	}
+
+	if result.Probes != 100 {
+		t.Errorf("expect 100 probes, got %d", result.Probes)
+	}
+
+	// repair then check again
+	opts.Repair = true
+	if result, err = bf.CheckBase62ID(ctx, source, opts); err != nil {
+		t.Fatalf("CheckBase62ID: %v", err)
+	}
+
+	if result.Repaired != 5 {
+		t.Errorf("expect 5 repaired, got %d", result.Repaired)
+	}
+
+	if result, err = bf.CheckBase62ID(ctx, source, opts); err != nil {
+		t.Fatalf("CheckBase62ID: %v", err)
+	}
+
+	if result.Missing != 0 {
+		t.Errorf("expect no missing after repair, got %d", result.Missing)
+	}
+}
+
+func TestProbeFalsePositiveNoRoom(t *testing.T) {
+	ctx := context.Background()
+	cfg := &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  100,
+	}
+
+	bf, err := New(ctx, bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, maxID := range []snowflake.SID{math.MaxInt64 - probeIDGap, math.MaxInt64 - 1, math.MaxInt64} {
+		var result CheckResult
+		if err := bf.probeFalsePositive(ctx, maxID, 10, &result); err == nil {
+			t.Errorf("maxID %d: expect error when no id is left to probe", maxID)
+		}
+	}
+
+	var result CheckResult
+	if err := bf.probeFalsePositive(ctx, math.MaxInt64-probeIDGap-1, 10, &result); err != nil {
+		t.Fatalf("probeFalsePositive: %v", err)
+	}
+
+	if result.Probes != 10 {
+		t.Errorf("expect 10 probes, got %d", result.Probes)
+	}
+}
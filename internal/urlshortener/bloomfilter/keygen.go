@@ -1,13 +1,58 @@
 package bloomfilter
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+// base62IDHashTag is the redis cluster hash tag of base62ID filter.
+//
+// Key without "{}" is hashed as a whole, so the key "urlshortener:base62ID"
+// is in the same hash slot as any key tagged with "{urlshortener:base62ID}".
+// Keys that work together with the filter in one command (ex: RENAME)
+// should be created by genBase62IDTaggedKey.
+func base62IDHashTag() string {
+	return "urlshortener:base62ID"
+}
+
 // genBase62IDKey create key to store base62ID to bloom filter
 func genBase62IDKey() string {
-	key := "urlshortener:base62ID"
+	key := base62IDHashTag()
+	return key
+}
+
+// genBase62IDTaggedKey create key that is in the same hash slot
+// as genBase62IDKey
+func genBase62IDTaggedKey(suffix string) string {
+	return "{" + base62IDHashTag() + "}:" + suffix
+}
+
+// genBase62IDItem create item of u in base62ID filter.
+// It is base62 of ID no matter what ID_GEN_ALPHABET is,
+// so filter stays valid when the alphabet changes.
+func genBase62IDItem(u model.URL) string {
+	return u.ID.Base62()
+}
+
+// genLongURLKey create key to store digest of longURL to bloom filter.
+// It is also the hash tag of genLongURLTaggedKey, like base62IDHashTag.
+func genLongURLKey() string {
+	key := "urlshortener:longURL"
 	return key
 }
 
-// genLongURLKey create key to store  to bloom filter
-// func genLongURLKey() string {
-// 	key := "urlshortener:longURL"
-// 	return key
-// }
+// genLongURLTaggedKey create key that is in the same hash slot
+// as genLongURLKey
+func genLongURLTaggedKey(suffix string) string {
+	return "{" + genLongURLKey() + "}:" + suffix
+}
+
+// genLongURLItem create item of longURL in bloom filter.
+// Digest is used instead of longURL, so item size is fixed
+// no matter how long the url is.
+func genLongURLItem(longURL string) string {
+	sum := sha256.Sum256([]byte(longURL))
+	return hex.EncodeToString(sum[:])
+}
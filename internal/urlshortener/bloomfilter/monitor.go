@@ -0,0 +1,125 @@
+package bloomfilter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// CapacityMonitor check usage of base62 ID and longURL filters periodically,
+// and rebuild them into larger filters when one of them is almost full.
+//
+// Rebuild happens under a temporary key and is RENAMEd into place,
+// so redirects keep working during rebuild.
+type CapacityMonitor struct {
+	bf        *URLShortenerBloomFilter
+	source    URLSource
+	cfg       urlshortenerconfig.BloomFilterMonitorConfig
+	batchSize int
+}
+
+// NewCapacityMonitor create CapacityMonitor, call Run to start it.
+func NewCapacityMonitor(
+	bf *URLShortenerBloomFilter, source URLSource, cfg *urlshortenerconfig.Config,
+) *CapacityMonitor {
+	return &CapacityMonitor{
+		bf:        bf,
+		source:    source,
+		cfg:       cfg.BloomFilterMonitor,
+		batchSize: cfg.BloomFilterRebuildBatchSize,
+	}
+}
+
+// Run check filter every interval until ctx is done.
+func (m *CapacityMonitor) Run(ctx context.Context) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_monitor")
+
+	if m.cfg.Interval <= 0 {
+		logger.Info("bloom filter capacity monitor disabled")
+		return
+	}
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+func (m *CapacityMonitor) check(ctx context.Context) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_monitor")
+
+	var reasons []string
+
+	for _, filter := range []struct {
+		name  string
+		stats func(ctx context.Context) (FilterStats, error)
+	}{
+		{"base62 ID", m.bf.Base62IDStats},
+		{"longURL", m.bf.LongURLStats},
+	} {
+		stats, err := filter.stats(ctx)
+		if err != nil {
+			logger.Warnf("read %s filter stats: %s", filter.name, err.Error())
+			return
+		}
+
+		logger.Infow("bloom filter stats",
+			"filter", filter.name,
+			"capacity", stats.Capacity,
+			"items", stats.Items,
+			"filters", stats.Filters,
+			"fill_ratio", stats.FillRatio,
+			"estimated_false_positive_rate", stats.EstimatedFalsePositiveRate,
+		)
+
+		if reason := m.rebuildReason(stats); reason != "" {
+			reasons = append(reasons, filter.name+" "+reason)
+		}
+	}
+
+	if len(reasons) == 0 {
+		return
+	}
+
+	reason := strings.Join(reasons, ", ")
+
+	if !m.cfg.AutoRebuild {
+		logger.Warnf("bloom filter need rebuild (%s), auto rebuild is disabled", reason)
+		return
+	}
+
+	logger.Warnf("bloom filter need rebuild (%s), rebuilding", reason)
+
+	if _, err := m.bf.Rebuild(ctx, m.source, m.batchSize); err != nil {
+		if errors.Is(err, ErrRebuildRunning) {
+			logger.Info("bloom filter rebuild is already running")
+			return
+		}
+		logger.Errorf("Rebuild: %s", err.Error())
+	}
+}
+
+// rebuildReason return why filter should be rebuilt, empty if it should not
+func (m *CapacityMonitor) rebuildReason(stats FilterStats) string {
+	switch {
+	case stats.Filters > 1:
+		return "filter has scaled up"
+	case m.cfg.MaxFillRatio > 0 && stats.FillRatio >= m.cfg.MaxFillRatio:
+		return "fill ratio crossed threshold"
+	case m.cfg.MaxFalsePositiveRate > 0 && stats.EstimatedFalsePositiveRate >= m.cfg.MaxFalsePositiveRate:
+		return "estimated false positive rate crossed threshold"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,128 @@
+package bloomfilter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TinyMurky/snowflake"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+)
+
+func TestCapacityMonitor_RebuildWhenFull(t *testing.T) {
+	ctx := context.Background()
+	cfg := &urlshortenerconfig.Config{
+		BloomFilter: bloomfilter.Config{
+			RedisBloomFilterErrorRate: 0.01,
+			RedisBloomFilterCapacity:  10,
+		},
+		BloomFilterMonitor: urlshortenerconfig.BloomFilterMonitorConfig{
+			MaxFillRatio: 0.9,
+			AutoRebuild:  true,
+		},
+		BloomFilterRebuildBatchSize: 100,
+	}
+
+	filter := bloomfilter.NewMemoryFilter(0.01, 10)
+	bf, err := New(ctx, filter, cfg.BloomFilterConfig())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	source := &fakeSource{}
+	for i := 1; i <= 9; i++ {
+		id := snowflake.SID(i)
+		source.ids = append(source.ids, id)
+		if err := bf.AddURL(ctx, fakeURL(id)); err != nil {
+			t.Fatalf("AddURL: %v", err)
+		}
+	}
+
+	monitor := NewCapacityMonitor(bf, source, cfg)
+	monitor.check(ctx)
+
+	stats, err := bf.Base62IDStats(ctx)
+	if err != nil {
+		t.Fatalf("Base62IDStats: %v", err)
+	}
+
+	if stats.Capacity != 18 {
+		t.Errorf("Capacity: expect rebuilt to 18, got %d", stats.Capacity)
+	}
+
+	if stats.Items != 9 {
+		t.Errorf("Items: expect 9, got %d", stats.Items)
+	}
+}
+
+func TestCapacityMonitor_RebuildWhenLongURLFull(t *testing.T) {
+	ctx := context.Background()
+	cfg := &urlshortenerconfig.Config{
+		BloomFilter: bloomfilter.Config{
+			RedisBloomFilterErrorRate: 0.01,
+			RedisBloomFilterCapacity:  10,
+		},
+		BloomFilterMonitor: urlshortenerconfig.BloomFilterMonitorConfig{
+			MaxFillRatio: 0.9,
+			AutoRebuild:  true,
+		},
+		BloomFilterRebuildBatchSize: 100,
+	}
+
+	bf, err := New(ctx, bloomfilter.NewMemoryFilter(0.01, 10), cfg.BloomFilterConfig())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// only longURL filter is almost full
+	source := &fakeSource{}
+	for i := 1; i <= 9; i++ {
+		id := snowflake.SID(i)
+		source.ids = append(source.ids, id)
+		if err := bf.AddLongURL(ctx, fakeURL(id)); err != nil {
+			t.Fatalf("AddLongURL: %v", err)
+		}
+	}
+
+	NewCapacityMonitor(bf, source, cfg).check(ctx)
+
+	stats, err := bf.LongURLStats(ctx)
+	if err != nil {
+		t.Fatalf("LongURLStats: %v", err)
+	}
+
+	if stats.Capacity != 18 {
+		t.Errorf("Capacity: expect rebuilt to 18, got %d", stats.Capacity)
+	}
+
+	if ok, _ := bf.IsURLBase62IDExist(ctx, fakeURL(1)); !ok {
+		t.Error("base62 ID filter should be rebuilt as well")
+	}
+}
+
+func TestCapacityMonitor_RebuildReason(t *testing.T) {
+	m := &CapacityMonitor{
+		cfg: urlshortenerconfig.BloomFilterMonitorConfig{
+			MaxFillRatio:         0.9,
+			MaxFalsePositiveRate: 0.01,
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		stats      FilterStats
+		wantReason bool
+	}{
+		{"healthy", FilterStats{Filters: 1, FillRatio: 0.5, EstimatedFalsePositiveRate: 0.001}, false},
+		{"scaled up", FilterStats{Filters: 2, FillRatio: 0.5}, true},
+		{"almost full", FilterStats{Filters: 1, FillRatio: 0.95}, true},
+		{"false positive", FilterStats{Filters: 1, FillRatio: 0.5, EstimatedFalsePositiveRate: 0.02}, true},
+	}
+
+	for _, tc := range testCases {
+		if got := m.rebuildReason(tc.stats) != ""; got != tc.wantReason {
+			t.Errorf("%s: expect rebuild %v, got %v", tc.name, tc.wantReason, got)
+		}
+	}
+}
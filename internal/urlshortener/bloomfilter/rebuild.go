@@ -0,0 +1,268 @@
+package bloomfilter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// rebuildCapacityFactor leave room for links created after rebuild
+const rebuildCapacityFactor = 2
+
+// rebuildCatchUpMargin is subtracted from start of rebuild when catching up by created_at,
+// created_at is stored in second and clock of instances may differ a little.
+const rebuildCatchUpMargin = time.Minute
+
+// ErrRebuildRunning is returned when a rebuild is already running in this process
+var ErrRebuildRunning = errors.New("bloom filter rebuild is already running")
+
+// rebuildRunning guard Rebuild, filter keys are process wide
+// so it is shared by every URLShortenerBloomFilter.
+var rebuildRunning atomic.Bool
+
+// IsRebuilding report whether Rebuild is running in this process
+func IsRebuilding() bool {
+	return rebuildRunning.Load()
+}
+
+// TryStartRebuild mark rebuild as running in this process, ok is false if it is already running.
+// Caller runs RebuildStarted and then release, ex: claim synchronously and rebuild in background.
+func TryStartRebuild() (release func(), ok bool) {
+	if !rebuildRunning.CompareAndSwap(false, true) {
+		return nil, false
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { rebuildRunning.Store(false) })
+	}, true
+}
+
+// URLSource provide all urls, it is implemented by database.URLShortenerDB.
+// Urls only need ID and LongURL.
+type URLSource interface {
+	CountURLs(ctx context.Context) (int64, error)
+	ListURLsAfter(ctx context.Context, afterID snowflake.SID, limit int) ([]model.URL, error)
+	ListURLsCreatedSince(ctx context.Context, since time.Time, afterID snowflake.SID, limit int) ([]model.URL, error)
+}
+
+// RebuildResult is the summary of a rebuild
+type RebuildResult struct {
+	Added    int64
+	Capacity int64
+	Duration time.Duration
+}
+
+// rebuildTarget is a filter rebuilt from urls
+type rebuildTarget struct {
+	key    string
+	tmpKey string
+	item   func(u model.URL) string
+}
+
+// Rebuild build fresh base62 ID and longURL filters from source and replace the current ones.
+//
+// Filters are built under temporary keys with batches of BF.MADD and
+// RENAMEd into place, so readers always see complete filters.
+// Urls created while building were added to the old filters, they are added
+// again after RENAME by streaming urls created since the rebuild started.
+// IDs are not increasing for every strategy (ex: random), so ids after
+// the last streamed one would miss them.
+//
+// Capacity of new filters is the larger one of config and
+// rebuildCapacityFactor times current number of urls.
+//
+// ErrRebuildRunning is returned if a rebuild is already running in this process.
+func (bf *URLShortenerBloomFilter) Rebuild(
+	ctx context.Context, source URLSource, batchSize int,
+) (RebuildResult, error) {
+	release, ok := TryStartRebuild()
+	if !ok {
+		return RebuildResult{}, ErrRebuildRunning
+	}
+	defer release()
+
+	return bf.RebuildStarted(ctx, source, batchSize)
+}
+
+// RebuildStarted is Rebuild for caller that already
+// took the rebuild by TryStartRebuild.
+func (bf *URLShortenerBloomFilter) RebuildStarted(
+	ctx context.Context, source URLSource, batchSize int,
+) (RebuildResult, error) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_rebuild")
+	start := time.Now()
+
+	if batchSize <= 0 {
+		return RebuildResult{}, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	count, err := source.CountURLs(ctx)
+	if err != nil {
+		return RebuildResult{}, fmt.Errorf("count urls: %w", err)
+	}
+
+	capacity := max(bf.cfg.RedisBloomFilterCapacity, count*rebuildCapacityFactor)
+
+	suffix := fmt.Sprintf("rebuild:%d", start.UnixNano())
+	targets := []rebuildTarget{
+		{
+			key:    genBase62IDKey(),
+			tmpKey: genBase62IDTaggedKey(suffix),
+			item:   func(u model.URL) string { return genBase62IDItem(u) },
+		},
+		{
+			key:    genLongURLKey(),
+			tmpKey: genLongURLTaggedKey(suffix),
+			item:   func(u model.URL) string { return genLongURLItem(u.LongURL) },
+		},
+	}
+
+	tmpKeys := make([]string, len(targets))
+	for i, target := range targets {
+		tmpKeys[i] = target.tmpKey
+	}
+
+	deleteTmpFilters := func() {
+		for _, tmpKey := range tmpKeys {
+			bf.deleteTmpFilter(ctx, tmpKey)
+		}
+	}
+
+	for _, target := range targets {
+		logger.Infof("rebuilding %s with %d urls, capacity %d, temporary key %s", target.key, count, capacity, target.tmpKey)
+
+		if err := bf.filter.Reserve(ctx, target.tmpKey, bf.cfg.RedisBloomFilterErrorRate, capacity); err != nil {
+			deleteTmpFilters()
+			return RebuildResult{}, fmt.Errorf("reserve %s: %w", target.tmpKey, err)
+		}
+	}
+
+	added, err := bf.addURLs(ctx, targets, tmpKeys, batchSize, source.ListURLsAfter)
+	if err != nil {
+		deleteTmpFilters()
+		return RebuildResult{}, fmt.Errorf("add urls to temporary filters: %w", err)
+	}
+
+	keys := make([]string, len(targets))
+	for i, target := range targets {
+		if err := bf.filter.Rename(ctx, target.tmpKey, target.key); err != nil {
+			deleteTmpFilters()
+			return RebuildResult{}, fmt.Errorf("rename %s to %s: %w", target.tmpKey, target.key, err)
+		}
+		keys[i] = target.key
+	}
+
+	// catch up urls inserted while streaming
+	since := start.Add(-rebuildCatchUpMargin)
+	caughtUp, err := bf.addURLs(ctx, targets, keys, batchSize, func(ctx context.Context, afterID snowflake.SID, limit int) ([]model.URL, error) {
+		return source.ListURLsCreatedSince(ctx, since, afterID, limit)
+	})
+	if err != nil {
+		return RebuildResult{}, fmt.Errorf("catch up urls created since %s: %w", since.UTC().Format(time.DateTime), err)
+	}
+
+	result := RebuildResult{
+		Added:    added + caughtUp,
+		Capacity: capacity,
+		Duration: time.Since(start),
+	}
+
+	logger.Infof("rebuilt %v with %d urls (%d caught up) in %s", keys, result.Added, caughtUp, result.Duration)
+
+	return result, nil
+}
+
+// RebuildIfEmpty rebuild filters if one of them has no item but source has urls,
+// ex: memory filter after restart without snapshot.
+// It reports whether filters are rebuilt.
+func (bf *URLShortenerBloomFilter) RebuildIfEmpty(
+	ctx context.Context, source URLSource, batchSize int,
+) (bool, error) {
+	empty := false
+	for _, key := range []string{genBase62IDKey(), genLongURLKey()} {
+		info, err := bf.filter.Info(ctx, key)
+		if err != nil {
+			return false, fmt.Errorf("info of %s: %w", key, err)
+		}
+		empty = empty || info.Items == 0
+	}
+
+	if !empty {
+		return false, nil
+	}
+
+	count, err := source.CountURLs(ctx)
+	if err != nil {
+		return false, fmt.Errorf("count urls: %w", err)
+	}
+
+	if count == 0 {
+		return false, nil
+	}
+
+	if _, err := bf.Rebuild(ctx, source, batchSize); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// listURLsFunc list at most limit urls with ID greater than afterID in ascending order
+type listURLsFunc func(ctx context.Context, afterID snowflake.SID, limit int) ([]model.URL, error)
+
+// addURLs stream urls of list page by page, items of targets[i] are added into keys[i].
+// It returns number of urls added.
+func (bf *URLShortenerBloomFilter) addURLs(
+	ctx context.Context, targets []rebuildTarget, keys []string, batchSize int, list listURLsFunc,
+) (int64, error) {
+	var (
+		added  int64
+		lastID snowflake.SID
+	)
+
+	for {
+		urls, err := list(ctx, lastID, batchSize)
+		if err != nil {
+			return added, fmt.Errorf("list urls after %d: %w", lastID, err)
+		}
+
+		if len(urls) == 0 {
+			return added, nil
+		}
+
+		for i, target := range targets {
+			items := make([]string, len(urls))
+			for j, u := range urls {
+				items[j] = target.item(u)
+			}
+
+			if err := bf.filter.AddMany(ctx, keys[i], items); err != nil {
+				return added, fmt.Errorf("add to %s: %w", keys[i], err)
+			}
+		}
+
+		added += int64(len(urls))
+		lastID = urls[len(urls)-1].ID
+	}
+}
+
+func (bf *URLShortenerBloomFilter) deleteTmpFilter(ctx context.Context, tmpKey string) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_rebuild")
+
+	// ctx might be the reason of failure
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := bf.filter.Delete(cleanupCtx, tmpKey); err != nil {
+		logger.Errorf("delete temporary filter %s: %s", tmpKey, err.Error())
+	}
+}
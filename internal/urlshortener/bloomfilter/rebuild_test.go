@@ -0,0 +1,172 @@
+package bloomfilter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+)
+
+// fakeSource is URLIDSource on sorted ids,
+// onList is called before each list to simulate concurrent insert.
+// ids not in created are created long ago.
+type fakeSource struct {
+	ids     []snowflake.SID
+	created map[snowflake.SID]time.Time
+	onList  func(s *fakeSource)
+}
+
+func (s *fakeSource) CountURLs(context.Context) (int64, error) {
+	return int64(len(s.ids)), nil
+}
+
+func (s *fakeSource) ListIDsAfter(_ context.Context, afterID snowflake.SID, limit int) ([]snowflake.SID, error) {
+	if s.onList != nil {
+		s.onList(s)
+	}
+
+	sort.Slice(s.ids, func(i, j int) bool { return s.ids[i] < s.ids[j] })
+
+	var ids []snowflake.SID
+	for _, id := range s.ids {
+		if id > afterID && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *fakeSource) ListURLsAfter(ctx context.Context, afterID snowflake.SID, limit int) ([]model.URL, error) {
+	return s.ListURLsCreatedSince(ctx, time.Time{}, afterID, limit)
+}
+
+func (s *fakeSource) ListURLsCreatedSince(ctx context.Context, since time.Time, afterID snowflake.SID, limit int) ([]model.URL, error) {
+	all, err := s.ListIDsAfter(ctx, afterID, len(s.ids))
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []model.URL
+	for _, id := range all {
+		if !s.created[id].Before(since) && len(urls) < limit {
+			urls = append(urls, fakeURL(id))
+		}
+	}
+	return urls, nil
+}
+
+// fakeURL is url of id in fakeSource
+func fakeURL(id snowflake.SID) model.URL {
+	return model.URL{ID: id, LongURL: fmt.Sprintf("https://example.com/%d", id)}
+}
+
+func TestRebuild(t *testing.T) {
+	ctx := context.Background()
+	cfg := &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  10,
+	}
+
+	filter := bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity)
+	bf, err := New(ctx, filter, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	source := &fakeSource{}
+	for i := 1; i <= 25; i++ {
+		source.ids = append(source.ids, snowflake.SID(i*1000))
+	}
+
+	// insert one id smaller than streamed ones while streaming,
+	// like random strategy does, it should be caught up
+	lists := 0
+	source.onList = func(s *fakeSource) {
+		lists++
+		if lists == 3 {
+			s.ids = append(s.ids, 500)
+			s.created = map[snowflake.SID]time.Time{500: time.Now()}
+		}
+	}
+
+	result, err := bf.Rebuild(ctx, source, 10)
+	if err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	if result.Added != 26 {
+		t.Errorf("Added: expect 26, got %d", result.Added)
+	}
+
+	if result.Capacity != 50 {
+		t.Errorf("Capacity: expect 50, got %d", result.Capacity)
+	}
+
+	for _, id := range source.ids {
+		ok, err := bf.IsURLBase62IDExist(ctx, fakeURL(id))
+		if err != nil {
+			t.Fatalf("IsURLBase62IDExist: %v", err)
+		}
+		if !ok {
+			t.Errorf("id %d not in rebuilt filter", id)
+		}
+
+		ok, err = bf.IsLongURLExist(ctx, fakeURL(id))
+		if err != nil {
+			t.Fatalf("IsLongURLExist: %v", err)
+		}
+		if !ok {
+			t.Errorf("longURL of id %d not in rebuilt filter", id)
+		}
+	}
+}
+
+func TestTryStartRebuild(t *testing.T) {
+	ctx := context.Background()
+	cfg := &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  10,
+	}
+
+	bf, err := New(ctx, bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	release, ok := TryStartRebuild()
+	if !ok {
+		t.Fatal("TryStartRebuild: expect ok")
+	}
+
+	if _, ok := TryStartRebuild(); ok {
+		t.Error("second TryStartRebuild should fail")
+	}
+
+	if _, err := bf.Rebuild(ctx, &fakeSource{}, 10); !errors.Is(err, ErrRebuildRunning) {
+		t.Errorf("expect ErrRebuildRunning, got %v", err)
+	}
+
+	if !IsRebuilding() {
+		t.Error("IsRebuilding should be true")
+	}
+
+	release()
+	// release twice does not clear a later rebuild
+	release2, ok := TryStartRebuild()
+	if !ok {
+		t.Fatal("TryStartRebuild after release: expect ok")
+	}
+	release()
+
+	if !IsRebuilding() {
+		t.Error("second release should be no-op")
+	}
+	release2()
+}
@@ -0,0 +1,166 @@
+package bloomfilter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// AddReplayer add base62 ID and longURL into bloom filter through circuit breaker.
+// If it failed (ex: redis is down), the url is queued and
+// replayed in background until bloom filter is back.
+//
+// Queue is in memory and bounded, url dropped from a full queue
+// or failed maxReplayAttempts times need bloom filter to be rebuilt from database.
+type AddReplayer struct {
+	bf       *URLShortenerBloomFilter
+	breaker  *circuitbreaker.Breaker
+	maxQueue int
+
+	mu      sync.Mutex
+	pending []pendingURL
+}
+
+// maxReplayAttempts is how many times an url is replayed before it is dropped.
+// Calls rejected by open breaker are not counted.
+const maxReplayAttempts = 10
+
+type pendingURL struct {
+	url      model.URL
+	attempts int
+}
+
+// NewAddReplayer create AddReplayer, call Run to start replay.
+func NewAddReplayer(bf *URLShortenerBloomFilter, breaker *circuitbreaker.Breaker, maxQueue int) *AddReplayer {
+	return &AddReplayer{
+		bf:       bf,
+		breaker:  breaker,
+		maxQueue: maxQueue,
+	}
+}
+
+// AddURL try to add base62 ID and longURL of url to bloom filter,
+// queue it for replay on failure.
+// It returns false if url is queued.
+func (r *AddReplayer) AddURL(ctx context.Context, u model.URL) bool {
+	logger := logging.FromContext(ctx).Named("bloomfilter_add_replayer")
+
+	err := r.breaker.Do(func() error {
+		return r.bf.AddURL(ctx, u)
+	})
+
+	if err == nil {
+		return true
+	}
+
+	logger.Warnf("add url %s to bloom filter failed, queued for replay: %s", u.GetIDBase62(), err.Error())
+	r.enqueue(ctx, pendingURL{url: u})
+
+	return false
+}
+
+// Pending return number of url waiting for replay
+func (r *AddReplayer) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// Run replays queued url every interval until ctx is done.
+// Replay is disabled if interval is not positive.
+func (r *AddReplayer) Run(ctx context.Context, interval time.Duration) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_add_replayer")
+
+	if interval <= 0 {
+		logger.Info("bloom filter add replay disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if n := r.Pending(); n > 0 {
+				logger.Errorf("bloom filter replayer stopped with %d url not replayed", n)
+			}
+			return
+		case <-ticker.C:
+			r.replay(ctx)
+		}
+	}
+}
+
+// replay try each queued url once. Failed url is moved to the back of queue,
+// so one url that keeps failing does not block the others.
+// Pass is stopped when breaker is open, the rest will fail the same way.
+func (r *AddReplayer) replay(ctx context.Context) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_add_replayer")
+
+	for range r.Pending() {
+		p, ok := r.pop()
+		if !ok {
+			return
+		}
+
+		err := r.breaker.Do(func() error {
+			return r.bf.AddURL(ctx, p.url)
+		})
+
+		switch {
+		case err == nil:
+			logger.Infof("replayed bloom filter add for base62 ID %s", p.url.GetIDBase62())
+		case errors.Is(err, circuitbreaker.ErrOpen):
+			logger.Debugf("replay bloom filter add stopped: %s", err.Error())
+			r.enqueue(ctx, p)
+			return
+		default:
+			p.attempts++
+			if p.attempts >= maxReplayAttempts {
+				logger.Errorf(
+					"replay bloom filter add for base62 ID %s failed %d times, dropped: %s",
+					p.url.GetIDBase62(), p.attempts, err.Error(),
+				)
+				continue
+			}
+
+			logger.Debugf("replay bloom filter add for base62 ID %s failed: %s", p.url.GetIDBase62(), err.Error())
+			r.enqueue(ctx, p)
+		}
+	}
+}
+
+func (r *AddReplayer) enqueue(ctx context.Context, p pendingURL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.pending) >= r.maxQueue {
+		logging.FromContext(ctx).Errorf(
+			"bloom filter replay queue is full (%d), base62 ID %s is dropped",
+			r.maxQueue, p.url.GetIDBase62(),
+		)
+		return
+	}
+
+	r.pending = append(r.pending, p)
+}
+
+func (r *AddReplayer) pop() (pendingURL, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.pending) == 0 {
+		return pendingURL{}, false
+	}
+
+	p := r.pending[0]
+	r.pending[0] = pendingURL{}
+	r.pending = r.pending[1:]
+	return p, true
+}
@@ -0,0 +1,80 @@
+package bloomfilter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+)
+
+func TestReplaySkipFailingURL(t *testing.T) {
+	ctx := context.Background()
+	cfg := &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  10,
+	}
+	bf, err := New(ctx, bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	breaker := circuitbreaker.New(ctx, "test", &circuitbreaker.Config{FailureThreshold: 100, OpenTimeout: time.Minute})
+	r := NewAddReplayer(bf, breaker, 10)
+
+	// empty longURL always fail to be added
+	bad := model.URL{ID: 1}
+	good := model.URL{ID: 2, LongURL: "https://example.com/a"}
+	r.enqueue(ctx, pendingURL{url: bad})
+	r.enqueue(ctx, pendingURL{url: good})
+
+	r.replay(ctx)
+
+	if ok, _ := bf.IsLongURLExist(ctx, good); !ok {
+		t.Error("url queued after failing url should be replayed")
+	}
+
+	if n := r.Pending(); n != 1 {
+		t.Fatalf("pending = %d, want 1", n)
+	}
+
+	for range maxReplayAttempts - 1 {
+		r.replay(ctx)
+	}
+
+	if n := r.Pending(); n != 0 {
+		t.Errorf("pending = %d, want 0 after %d attempts", n, maxReplayAttempts)
+	}
+}
+
+func TestReplayStopOnOpenBreaker(t *testing.T) {
+	ctx := context.Background()
+	cfg := &bloomfilter.Config{
+		RedisBloomFilterErrorRate: 0.001,
+		RedisBloomFilterCapacity:  10,
+	}
+	bf, err := New(ctx, bloomfilter.NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	breaker := circuitbreaker.New(ctx, "test", &circuitbreaker.Config{FailureThreshold: 1, OpenTimeout: time.Minute})
+	r := NewAddReplayer(bf, breaker, 10)
+
+	r.enqueue(ctx, pendingURL{url: model.URL{ID: 1}})
+	r.enqueue(ctx, pendingURL{url: model.URL{ID: 2, LongURL: "https://example.com/a"}})
+
+	// the first failure open breaker, the rest is kept for next pass
+	for range 2 * maxReplayAttempts {
+		r.replay(ctx)
+	}
+
+	if n := r.Pending(); n != 2 {
+		t.Errorf("pending = %d, want 2", n)
+	}
+
+	// disabled replay return at once
+	r.Run(ctx, 0)
+}
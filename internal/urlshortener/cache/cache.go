@@ -7,75 +7,184 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/redis/go-redis/v9"
-
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
 	"github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
 )
 
+// URLShortenerCache store url of url shortener in cache
 type URLShortenerCache struct {
-	cache *cache.Cache
+	cache cache.Cache
 }
 
-func New(c *cache.Cache) *URLShortenerCache {
+// New create URLShortenerCache on cache
+func New(c cache.Cache) *URLShortenerCache {
 	return &URLShortenerCache{
 		cache: c,
 	}
 }
 
-// SetLongURL set longURL from model.URL into
-func (uc *URLShortenerCache) SetLongURL(
+// SetURL set whole model.URL into cache
+func (uc *URLShortenerCache) SetURL(
 	ctx context.Context,
 	u model.URL,
 	expiration time.Duration,
 ) error {
 	// check validation
 	if u.IsZero() || u.ID == 0 {
-		return errors.New("SetLongURL: invalid model.URL or ID")
+		return errors.New("SetURL: invalid model.URL or ID")
 	}
 
 	if u.LongURL == "" {
-		return errors.New("SetLongURL: longURL is empty")
+		return errors.New("SetURL: longURL is empty")
+	}
+
+	value, err := encodeURL(u)
+	if err != nil {
+		return fmt.Errorf("SetURL: %w", err)
 	}
 
 	key := genURLKey(u)
-	return uc.set(ctx, key, u.LongURL, expiration)
+	return uc.set(ctx, key, value, expiration)
 }
 
-// GetLongURL get url model (with longURL) from cache
-func (uc *URLShortenerCache) GetLongURL(
+// SetURLs set many model.URL into cache in one round trip
+func (uc *URLShortenerCache) SetURLs(
+	ctx context.Context,
+	urls []model.URL,
+	expiration time.Duration,
+) error {
+	values := make(map[string]string, len(urls))
+
+	for _, u := range urls {
+		if u.IsZero() || u.ID == 0 || u.LongURL == "" {
+			return fmt.Errorf("SetURLs: invalid model.URL %q", u.GetIDBase62())
+		}
+
+		value, err := encodeURL(u)
+		if err != nil {
+			return fmt.Errorf("SetURLs: %w", err)
+		}
+
+		values[genURLKey(u)] = value
+	}
+
+	return uc.cache.SetMany(ctx, values, expiration)
+}
+
+// GetURL get url model from cache.
+// cache.ErrNotFound is returned if url is not in cache.
+//
+// Entry of legacy schema (long url only) is upgraded to current schema
+// with the same TTL on read.
+func (uc *URLShortenerCache) GetURL(
 	ctx context.Context,
 	u model.URL,
 ) (model.URL, error) {
 	if u.IsZero() || u.ID == 0 {
-		return u, errors.New("GetLongURL: invalid model.URL or ID")
+		return u, errors.New("GetURL: invalid model.URL or ID")
 	}
 
 	key := genURLKey(u)
-	longURL, err := uc.get(ctx, key)
+	value, err := uc.get(ctx, key)
+
+	if errors.Is(err, cache.ErrNotFound) {
+		return uc.getLegacyURL(ctx, u)
+	}
+
+	if err != nil {
+		return u, fmt.Errorf("GetURL failed for ID %s: %w", u.GetIDBase62(), err)
+	}
+
+	cached, err := decodeURL(value)
+	if err != nil {
+		return u, fmt.Errorf("GetURL decode failed for ID %s: %w", u.GetIDBase62(), err)
+	}
+
+	return cached, nil
+}
+
+// DelURL remove url from cache, both current and legacy key
+func (uc *URLShortenerCache) DelURL(
+	ctx context.Context,
+	u model.URL,
+) error {
+	if u.ID == 0 {
+		return errors.New("DelURL: invalid ID")
+	}
+
+	// keys are in different hash slot, so they are deleted one by one in cluster mode
+	if err := uc.cache.Del(ctx, genURLKey(u)); err != nil {
+		return fmt.Errorf("DelURL: %w", err)
+	}
+
+	if err := uc.cache.Del(ctx, genLegacyURLKey(u)); err != nil {
+		return fmt.Errorf("DelURL legacy: %w", err)
+	}
+
+	return nil
+}
+
+// getLegacyURL get url stored as plain long url string and
+// upgrade it to current schema.
+func (uc *URLShortenerCache) getLegacyURL(
+	ctx context.Context,
+	u model.URL,
+) (model.URL, error) {
+	logger := logging.FromContext(ctx).Named("urlshortener_cache")
+
+	legacyKey := genLegacyURLKey(u)
+	longURL, err := uc.get(ctx, legacyKey)
 
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			return u, redis.Nil
+		if errors.Is(err, cache.ErrNotFound) {
+			return u, cache.ErrNotFound
 		}
-		return u, fmt.Errorf("GetLongURL failed for ID %s: %w", u.GetIDBase62(), err)
+		return u, fmt.Errorf("GetURL legacy failed for ID %s: %w", u.GetIDBase62(), err)
 	}
 
 	u.LongURL = longURL
+
+	if err := uc.upgradeLegacyURL(ctx, u, legacyKey); err != nil {
+		// value is still correct, next read will try again
+		logger.Warnf("upgrade legacy cache of ID %s: %s", u.GetIDBase62(), err.Error())
+	}
+
 	return u, nil
 }
 
+func (uc *URLShortenerCache) upgradeLegacyURL(
+	ctx context.Context,
+	u model.URL,
+	legacyKey string,
+) error {
+	ttl, err := uc.cache.TTL(ctx, legacyKey)
+
+	// legacy key expired between Get and TTL
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("ttl: %w", err)
+	}
+
+	if err := uc.SetURL(ctx, u, ttl); err != nil {
+		return err
+	}
+
+	return uc.cache.Del(ctx, legacyKey)
+}
+
 func (uc *URLShortenerCache) set(
 	ctx context.Context,
 	key string,
-	value any,
+	value string,
 	expiration time.Duration,
 ) error {
-	return uc.cache.RDB.Set(
-		ctx, key, value, expiration,
-	).Err()
+	return uc.cache.Set(ctx, key, value, expiration)
 }
 
 func (uc *URLShortenerCache) get(ctx context.Context, key string) (string, error) {
-	return uc.cache.RDB.Get(ctx, key).Result()
+	return uc.cache.Get(ctx, key)
 }
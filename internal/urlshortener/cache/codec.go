@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+// urlSchemaVersion is the version of url stored in cache.
+// It is part of the key from genURLKey, bump it when cachedURL changed
+// in a way that old value can not be decoded.
+//
+//   - v1: plain long url string (legacy, see genLegacyURLKey)
+//   - v2: json of cachedURL
+const urlSchemaVersion = 2
+
+// cachedURL is the compact json form of model.URL in cache
+type cachedURL struct {
+	ID        int64  `json:"i"`
+	LongURL   string `json:"u"`
+	CreatedAt int64  `json:"c,omitempty"` // unix milli second
+}
+
+// encodeURL encode model.URL to store in cache
+func encodeURL(u model.URL) (string, error) {
+	c := cachedURL{
+		ID:      int64(u.ID),
+		LongURL: u.LongURL,
+	}
+
+	if !u.CreatedAt.IsZero() {
+		c.CreatedAt = u.CreatedAt.UnixMilli()
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("json marshal cached url: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// decodeURL decode value from encodeURL into model.URL
+func decodeURL(value string) (model.URL, error) {
+	var c cachedURL
+
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		return model.URL{}, fmt.Errorf("json unmarshal cached url: %w", err)
+	}
+
+	u := model.URL{
+		ID:      snowflake.SID(c.ID),
+		LongURL: c.LongURL,
+	}
+
+	if c.CreatedAt != 0 {
+		u.CreatedAt = time.UnixMilli(c.CreatedAt)
+	}
+
+	return u, nil
+}
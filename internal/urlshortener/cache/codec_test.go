@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+func TestEncodeDecodeURL(t *testing.T) {
+	testCases := []struct {
+		name string
+		url  model.URL
+	}{
+		{
+			name: "full url",
+			url: model.URL{
+				ID:        123456789,
+				LongURL:   "https://example.com/path?q=1",
+				CreatedAt: time.UnixMilli(1765670400123),
+			},
+		},
+		{
+			name: "without created at",
+			url: model.URL{
+				ID:      1,
+				LongURL: "https://example.com",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := encodeURL(tc.url)
+			if err != nil {
+				t.Fatalf("encodeURL: %v", err)
+			}
+
+			got, err := decodeURL(value)
+			if err != nil {
+				t.Fatalf("decodeURL: %v", err)
+			}
+
+			if got.ID != tc.url.ID {
+				t.Errorf("ID: expect %d, got %d", tc.url.ID, got.ID)
+			}
+
+			if got.LongURL != tc.url.LongURL {
+				t.Errorf("LongURL: expect %q, got %q", tc.url.LongURL, got.LongURL)
+			}
+
+			if !got.CreatedAt.Equal(tc.url.CreatedAt) {
+				t.Errorf("CreatedAt: expect %v, got %v", tc.url.CreatedAt, got.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestDecodeURL_Invalid(t *testing.T) {
+	// legacy value is plain long url
+	if _, err := decodeURL("https://example.com"); err == nil {
+		t.Error("expect error on non json value")
+	}
+}
+
+func TestGenURLKey(t *testing.T) {
+	u := model.URL{ID: 62}
+
+	if got, want := genURLKey(u), "urlshortener:url:v2:base62ID:{10}"; got != want {
+		t.Errorf("genURLKey: expect %q, got %q", want, got)
+	}
+
+	if got, want := genLegacyURLKey(u), "urlshortener:url:base62ID:10"; got != want {
+		t.Errorf("genLegacyURLKey: expect %q, got %q", want, got)
+	}
+
+	// key does not follow ID_GEN_ALPHABET
+	t.Cleanup(func() { model.SetAlphabet(model.AlphabetBase62) })
+	model.SetAlphabet(model.AlphabetBase32)
+
+	if got, want := genURLKey(u), "urlshortener:url:v2:base62ID:{10}"; got != want {
+		t.Errorf("genURLKey of base32: expect %q, got %q", want, got)
+	}
+}
@@ -6,9 +6,25 @@ import (
 	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
 )
 
-// genURLKey create key to store url in cache
+// genURLKey create key to store url in cache,
+// schema version of value is part of the key.
+//
+// base62ID is wrapped in "{}" as redis cluster hash tag,
+// so every key of the same url is in the same hash slot
+// and keys of different urls spread over the cluster.
+//
+// ID is always encoded as base62, not by ID_GEN_ALPHABET,
+// so cache stays valid when the alphabet changes.
 func genURLKey(u model.URL) string {
-	base62ID := u.GetIDBase62()
+	base62ID := u.ID.Base62()
+	key := fmt.Sprintf("urlshortener:url:v%d:base62ID:{%s}", urlSchemaVersion, base62ID)
+	return key
+}
+
+// genLegacyURLKey create key of v1 schema, which store only long url.
+// v1 only has base62 ID, no matter what ID_GEN_ALPHABET is.
+func genLegacyURLKey(u model.URL) string {
+	base62ID := u.ID.Base62()
 	key := fmt.Sprintf("urlshortener:url:base62ID:%s", base62ID)
 	return key
 }
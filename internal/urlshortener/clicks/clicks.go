@@ -0,0 +1,124 @@
+// Package clicks count redirects of urls.
+//
+// Clicks are counted in memory and flushed to database in one transaction
+// every interval, so redirect never waits for a write.
+// Click count in database is behind by at most one interval of every replica.
+package clicks
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// flushTimeout is the time given to the last flush after ctx is done
+const flushTimeout = 5 * time.Second
+
+// Store persist clicks, it is implemented by database.URLShortenerDB
+type Store interface {
+	AddClicks(ctx context.Context, clicks map[snowflake.SID]int64) error
+}
+
+// Counter count clicks in memory, call Run to flush them.
+type Counter struct {
+	store Store
+
+	mu      sync.Mutex
+	pending map[snowflake.SID]int64
+}
+
+// New create Counter that flush to store
+func New(store Store) *Counter {
+	return &Counter{
+		store:   store,
+		pending: make(map[snowflake.SID]int64),
+	}
+}
+
+// Add count one click of id
+func (c *Counter) Add(id snowflake.SID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[id]++
+}
+
+// Pending return number of urls that have clicks not flushed
+func (c *Counter) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+// Run flush clicks every interval until ctx is done,
+// clicks left are flushed once more before it returns.
+// Interval not positive disables periodic flush, clicks are flushed only when ctx is done.
+func (c *Counter) Run(ctx context.Context, interval time.Duration) {
+	logger := logging.FromContext(ctx).Named("clicks")
+
+	// nil channel never fires
+	var tick <-chan time.Time
+
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	} else {
+		logger.Warn("periodic click flush disabled, clicks are flushed on shutdown only")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flushTimeout)
+			defer cancel()
+
+			if err := c.Flush(flushCtx); err != nil {
+				logger.Errorf("click counter stopped with %d urls not flushed: %s", c.Pending(), err.Error())
+			}
+			return
+		case <-tick:
+			if err := c.Flush(ctx); err != nil {
+				logger.Warnf("flush clicks failed, retry next interval: %s", err.Error())
+			}
+		}
+	}
+}
+
+// Flush write pending clicks to store.
+// Clicks are kept for next flush if it failed.
+func (c *Counter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	clicks := c.pending
+	c.pending = make(map[snowflake.SID]int64)
+	c.mu.Unlock()
+
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	if err := c.store.AddClicks(ctx, clicks); err != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// merge back, clicks counted during flush are kept as well
+		for id, n := range c.pending {
+			clicks[id] += n
+		}
+		c.pending = clicks
+		return err
+	}
+
+	return nil
+}
+
+// snapshot return copy of pending clicks, for test
+func (c *Counter) snapshot() map[snowflake.SID]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.pending)
+}
@@ -0,0 +1,96 @@
+package clicks
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/TinyMurky/snowflake"
+)
+
+type fakeStore struct {
+	err     error
+	flushed map[snowflake.SID]int64
+}
+
+func (s *fakeStore) AddClicks(_ context.Context, clicks map[snowflake.SID]int64) error {
+	if s.err != nil {
+		return s.err
+	}
+	for id, n := range clicks {
+		s.flushed[id] += n
+	}
+	return nil
+}
+
+func TestCounterFlush(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{flushed: map[snowflake.SID]int64{}}
+	c := New(store)
+
+	c.Add(1)
+	c.Add(1)
+	c.Add(2)
+
+	if err := c.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := map[snowflake.SID]int64{1: 2, 2: 1}
+	if !maps.Equal(store.flushed, want) {
+		t.Errorf("flushed = %v, want %v", store.flushed, want)
+	}
+
+	if n := c.Pending(); n != 0 {
+		t.Errorf("Pending = %d after flush, want 0", n)
+	}
+}
+
+func TestCounterFlushFailedKeepsClicks(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{flushed: map[snowflake.SID]int64{}, err: errors.New("database is locked")}
+	c := New(store)
+
+	c.Add(1)
+	if err := c.Flush(ctx); err == nil {
+		t.Fatal("Flush: expect error")
+	}
+	c.Add(1)
+
+	want := map[snowflake.SID]int64{1: 2}
+	if got := c.snapshot(); !maps.Equal(got, want) {
+		t.Errorf("pending = %v, want %v", got, want)
+	}
+
+	store.err = nil
+	if err := c.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if !maps.Equal(store.flushed, want) {
+		t.Errorf("flushed = %v, want %v", store.flushed, want)
+	}
+}
+
+func TestCounterRunFlushDisabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := &fakeStore{flushed: map[snowflake.SID]int64{}}
+	c := New(store)
+
+	c.Add(1)
+
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx, 0)
+		close(done)
+	}()
+
+	cancel()
+	<-done
+
+	want := map[snowflake.SID]int64{1: 1}
+	if !maps.Equal(store.flushed, want) {
+		t.Errorf("flushed = %v, want %v", store.flushed, want)
+	}
+}
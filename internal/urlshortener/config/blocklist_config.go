@@ -0,0 +1,16 @@
+package urlshortenerconfig
+
+import "time"
+
+// BlocklistConfig is the config of long url blocklist.
+// Rules are loaded from File and from RedisKey of cache, one rule per line.
+type BlocklistConfig struct {
+	// File is the path of blocklist file, it is skipped if empty
+	File string `env:"BLOCKLIST_FILE"`
+
+	// RedisKey is the cache key holding rules, it is skipped if empty
+	RedisKey string `env:"BLOCKLIST_REDIS_KEY, default=urlshortener:blocklist"`
+
+	// ReloadInterval is how often rules are reloaded, 0 disable reload
+	ReloadInterval time.Duration `env:"BLOCKLIST_RELOAD_INTERVAL, default=30s"`
+}
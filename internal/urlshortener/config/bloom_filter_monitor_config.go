@@ -0,0 +1,21 @@
+package urlshortenerconfig
+
+import "time"
+
+// BloomFilterMonitorConfig is the config of checking bloom filter capacity periodically
+type BloomFilterMonitorConfig struct {
+	// Interval of checking BF.INFO, 0 disables the monitor
+	Interval time.Duration `env:"BLOOM_FILTER_MONITOR_INTERVAL, default=1m"`
+
+	// MaxFillRatio is inserted items / capacity that triggers rebuild
+	MaxFillRatio float64 `env:"BLOOM_FILTER_MAX_FILL_RATIO, default=0.9"`
+
+	// MaxFalsePositiveRate is estimated false positive rate that triggers rebuild,
+	// 0 means only fill ratio is checked
+	MaxFalsePositiveRate float64 `env:"BLOOM_FILTER_MAX_FALSE_POSITIVE_RATE, default=0"`
+
+	// AutoRebuild rebuild filter into larger one when threshold is crossed,
+	// otherwise it is only logged. Rebuild is not coordinated across replicas,
+	// so it is off by default and should be enabled on one of them only.
+	AutoRebuild bool `env:"BLOOM_FILTER_AUTO_REBUILD, default=false"`
+}
@@ -0,0 +1,19 @@
+package urlshortenerconfig
+
+import "time"
+
+// CacheWarmUpConfig is the config of loading links into cache on start up
+type CacheWarmUpConfig struct {
+	Enabled bool `env:"CACHE_WARMUP_ENABLED, default=true"`
+
+	// Limit is the number of newest links to load
+	Limit int `env:"CACHE_WARMUP_LIMIT, default=10000"`
+
+	// BatchSize is the number of links set in one pipeline
+	BatchSize int `env:"CACHE_WARMUP_BATCH_SIZE, default=500"`
+
+	// Timeout is the time budget of warm up,
+	// server starts with partly warmed cache when it is exceeded.
+	// 0 or negative means no time budget.
+	Timeout time.Duration `env:"CACHE_WARMUP_TIMEOUT, default=10s"`
+}
@@ -2,24 +2,53 @@
 package urlshortenerconfig
 
 import (
+	"time"
+
 	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
 	"github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
 	"github.com/TinyMurky/tinyurl/pkg/database"
+	"github.com/TinyMurky/tinyurl/pkg/nodelease"
+	"github.com/TinyMurky/tinyurl/pkg/retry"
 	"github.com/TinyMurky/tinyurl/pkg/singleflight"
 )
 
 // Config for urlshortener
 // it need to use github.com/sethvargo/go-envconfig package to read
 type Config struct {
-	Database     database.Config
-	Cache        cache.Config
-	BloomFilter  bloomfilter.Config
-	SingleFlight singleflight.Config
+	Database       database.Config
+	Cache          cache.Config
+	BloomFilter    bloomfilter.Config
+	SingleFlight   singleflight.Config
+	CircuitBreaker circuitbreaker.Config
+	StartupRetry   retry.Config `env:", prefix=STARTUP_RETRY_"`
+	NodeLease      nodelease.Config
 
 	IDGenerator            IDGeneratorConfig
+	CacheWarmUp            CacheWarmUpConfig
+	BloomFilterMonitor     BloomFilterMonitorConfig
+	Blocklist              BlocklistConfig
+	LinkList               LinkListConfig
 	Port                   string `env:"PORT"`
 	ShortURLPrefix         string `env:"SHORT_URL_PREFIX, default=http://localhost:3000"`
 	RedisCacheTTLInMiliSec int    `env:"SHORT_URL_CACHE_TTL_IN_MILI_SEC, default=300000"`
+
+	// bloom filter adds that failed on write path are queued and replayed
+	BloomFilterReplayInterval  time.Duration `env:"BLOOM_FILTER_REPLAY_INTERVAL, default=5s"`
+	BloomFilterReplayQueueSize int           `env:"BLOOM_FILTER_REPLAY_QUEUE_SIZE, default=10000"`
+
+	BloomFilterRebuildBatchSize int `env:"BLOOM_FILTER_REBUILD_BATCH_SIZE, default=1000"`
+
+	// ClickFlushInterval is how often click counts of redirects are written to database
+	ClickFlushInterval time.Duration `env:"CLICK_FLUSH_INTERVAL, default=5s"`
+
+	// LongURLFilterEnabled let shorten skip the longURL query
+	// when longURL filter says it is not exist
+	LongURLFilterEnabled bool `env:"LONG_URL_FILTER_ENABLED, default=true"`
+
+	// AdminAPIToken is the bearer token of /api/v1/admin/* endpoints,
+	// they are disabled if it is empty.
+	AdminAPIToken string `env:"ADMIN_API_TOKEN"`
 }
 
 // DatabaseConfig return Database config
@@ -41,3 +70,18 @@ func (c *Config) BloomFilterConfig() *bloomfilter.Config {
 func (c *Config) SingleFlightConfig() *singleflight.Config {
 	return &c.SingleFlight
 }
+
+// CircuitBreakerConfig return the config of circuit breaker
+func (c *Config) CircuitBreakerConfig() *circuitbreaker.Config {
+	return &c.CircuitBreaker
+}
+
+// StartupRetryConfig return the retry config of connecting dependencies on start up
+func (c *Config) StartupRetryConfig() *retry.Config {
+	return &c.StartupRetry
+}
+
+// NodeLeaseConfig return the config of leasing snowflake node ID
+func (c *Config) NodeLeaseConfig() *nodelease.Config {
+	return &c.NodeLease
+}
@@ -1,8 +1,64 @@
 package urlshortenerconfig
 
+import "time"
+
 type IDGeneratorConfig struct {
 	NodeID int64 `env:"ID_GEN_NODE_ID, default=1"`
 
 	// It should be YYYY-MM-DD
 	EpochTimeStartFrom string `env:"ID_GEN_EPOCH_TIME_START_FROM, default=2025-12-14"`
+
+	// Strategy decide how ID of new url is chosen:
+	// snowflake | random | hash | counter
+	Strategy string `env:"ID_GEN_STRATEGY, default=snowflake"`
+
+	// CodeLength is the length of code of random strategy,
+	// and the shortest code of hash strategy, at most 10 (12 for base32)
+	CodeLength int `env:"ID_GEN_CODE_LENGTH, default=7"`
+
+	// Alphabet of codes: base62 | base58 | base32 (Crockford, case-insensitive).
+	// base58 and base32 have no look-alike characters like "0" and "O".
+	Alphabet string `env:"ID_GEN_ALPHABET, default=base62"`
+
+	// MaxAttempts is how many codes random strategy try before giving up
+	MaxAttempts int `env:"ID_GEN_MAX_ATTEMPTS, default=5"`
+
+	// ObfuscationKey is the secret key of permuting ID into code,
+	// so codes are not time ordered. Empty disables obfuscation.
+	// Permuted ID spans 63 bits, so codes of every strategy are about 11 characters + version prefix,
+	// CodeLength of random and counter is not kept.
+	ObfuscationKey string `env:"ID_GEN_OBFUSCATION_KEY"`
+
+	// LegacyCodeMaxID is the largest ID still reachable by plain code
+	// after obfuscation is enabled, negative rejects every plain code.
+	// It is required with ObfuscationKey, otherwise plain codes would still enumerate every link.
+	LegacyCodeMaxID int64 `env:"ID_GEN_LEGACY_CODE_MAX_ID, default=0"`
+
+	// CheckCharEnabled append a check character to new codes,
+	// so mistyped codes are rejected without looking them up
+	CheckCharEnabled bool `env:"ID_GEN_CHECK_CHAR_ENABLED, default=false"`
+
+	// ReservedCodes are names of routes, they are never generated.
+	// Matched case-insensitively.
+	ReservedCodes []string `env:"ID_GEN_RESERVED_CODES, default=api,admin,health,healthz,metrics,static,assets,login,logout,shorturl,links,docs,openapi"`
+
+	// BlockedWords are skipped when they are part of a generated code,
+	// matched case-insensitively with digits read as look-alike letters ("5h1t").
+	BlockedWords []string `env:"ID_GEN_BLOCKED_WORDS, default=fuck,shit,cunt,cock,dick,piss,porn,slut,whore,bitch,nazi,rape,nigg,fag,kkk"`
+
+	// BlockedWordsFile has more blocked words, one per line
+	BlockedWordsFile string `env:"ID_GEN_BLOCKED_WORDS_FILE"`
+
+	// MaxClockBackwardWait is how long NextID waits for clock moved backwards,
+	// ID generation fails if clock is behind longer than it.
+	MaxClockBackwardWait time.Duration `env:"ID_GEN_MAX_CLOCK_BACKWARD_WAIT, default=5s"`
+
+	// HighWaterMarkEnabled persist the largest timestamp issued by node in database,
+	// so clock moved backwards across restart is detected. It needs id_high_water_marks migration.
+	HighWaterMarkEnabled bool `env:"ID_GEN_HIGH_WATER_MARK_ENABLED, default=false"`
+
+	// HighWaterMarkAhead is how far the persisted mark is ahead of clock,
+	// mark is saved at most once per HighWaterMarkAhead,
+	// and a restart waits up to it before generating ID.
+	HighWaterMarkAhead time.Duration `env:"ID_GEN_HIGH_WATER_MARK_AHEAD, default=2s"`
 }
@@ -0,0 +1,10 @@
+package urlshortenerconfig
+
+// LinkListConfig is the config of listing links by GET /api/v1/links
+type LinkListConfig struct {
+	// PageSize is the number of links of a page when limit is not given
+	PageSize int `env:"LINK_LIST_PAGE_SIZE, default=50"`
+
+	// MaxPageSize is the largest limit a request can ask for
+	MaxPageSize int `env:"LINK_LIST_MAX_PAGE_SIZE, default=500"`
+}
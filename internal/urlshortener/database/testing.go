@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/TinyMurky/tinyurl/pkg/database"
+)
+
+// NewTestDatabase create in memory sqlite database with all up migrations applied,
+// it is closed when test ends.
+func NewTestDatabase(tb testing.TB) *database.DB {
+	tb.Helper()
+
+	pool, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		tb.Fatalf("open sqlite: %v", err)
+	}
+	// every connection of :memory: is a different database
+	pool.SetMaxOpenConns(1)
+	tb.Cleanup(func() { pool.Close() })
+
+	_, file, _, _ := runtime.Caller(0)
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(file), "../../../migrations/*.up.sql"))
+	if err != nil {
+		tb.Fatalf("glob migrations: %v", err)
+	}
+	slices.Sort(files)
+
+	for _, file := range files {
+		query, err := os.ReadFile(file)
+		if err != nil {
+			tb.Fatalf("read %s: %v", file, err)
+		}
+
+		if _, err := pool.Exec(string(query)); err != nil {
+			tb.Fatalf("migrate %s: %v", file, err)
+		}
+	}
+
+	return &database.DB{Pool: pool}
+}
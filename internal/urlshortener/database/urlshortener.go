@@ -6,6 +6,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/TinyMurky/snowflake"
 
@@ -83,6 +85,245 @@ func (db *URLShortenerDB) GetFirstByLongURL(ctx context.Context, longURL string)
 	return urlFromDB, nil
 }
 
+// ListLatest will get at most limit urls, newest first.
+// snowflake id is time ordered, so it is sorted by primary key.
+func (db *URLShortenerDB) ListLatest(ctx context.Context, limit int) ([]model.URL, error) {
+	query := `
+		SELECT id, long_url, created_at
+		FROM urls
+		ORDER BY id DESC
+		LIMIT ?;
+	`
+
+	rows, err := db.db.Pool.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ListLatest query error: %w", err)
+	}
+	defer rows.Close()
+
+	urls := make([]model.URL, 0, limit)
+
+	for rows.Next() {
+		var u model.URL
+		if err := rows.Scan(&u.ID, &u.LongURL, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ListLatest scan error: %w", err)
+		}
+		urls = append(urls, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListLatest rows error: %w", err)
+	}
+
+	return urls, nil
+}
+
+// CountURLs return number of urls
+func (db *URLShortenerDB) CountURLs(ctx context.Context) (int64, error) {
+	var count int64
+
+	query := `SELECT COUNT(*) FROM urls;`
+
+	if err := db.db.Pool.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("CountURLs scan error: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListIDsAfter will get at most limit ids greater than afterID in ascending order.
+// It is used to stream all ids page by page without holding a long read.
+func (db *URLShortenerDB) ListIDsAfter(ctx context.Context, afterID snowflake.SID, limit int) ([]snowflake.SID, error) {
+	query := `
+		SELECT id
+		FROM urls
+		WHERE id > ?
+		ORDER BY id ASC
+		LIMIT ?;
+	`
+
+	rows, err := db.db.Pool.QueryContext(ctx, query, int64(afterID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("ListIDsAfter query error: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]snowflake.SID, 0, limit)
+
+	for rows.Next() {
+		var id snowflake.SID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ListIDsAfter scan error: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListIDsAfter rows error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListURLsAfter will get at most limit urls with ID greater than afterID in ascending order,
+// only ID and LongURL are read.
+// It is used to stream all urls page by page without holding a long read.
+func (db *URLShortenerDB) ListURLsAfter(ctx context.Context, afterID snowflake.SID, limit int) ([]model.URL, error) {
+	query := `
+		SELECT id, long_url
+		FROM urls
+		WHERE id > ?
+		ORDER BY id ASC
+		LIMIT ?;
+	`
+
+	urls, err := db.listIDAndLongURL(ctx, limit, query, int64(afterID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("ListURLsAfter %w", err)
+	}
+
+	return urls, nil
+}
+
+// ListURLsCreatedSince will get at most limit urls created at or after since
+// and with ID greater than afterID in ascending order, only ID and LongURL are read.
+// since is compared in second, the precision created_at is stored in.
+func (db *URLShortenerDB) ListURLsCreatedSince(
+	ctx context.Context, since time.Time, afterID snowflake.SID, limit int,
+) ([]model.URL, error) {
+	query := `
+		SELECT id, long_url
+		FROM urls
+		WHERE created_at >= ? AND id > ?
+		ORDER BY id ASC
+		LIMIT ?;
+	`
+
+	urls, err := db.listIDAndLongURL(ctx, limit, query, since.UTC().Format(time.DateTime), int64(afterID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("ListURLsCreatedSince %w", err)
+	}
+
+	return urls, nil
+}
+
+// listIDAndLongURL run query that select id and long_url
+func (db *URLShortenerDB) listIDAndLongURL(ctx context.Context, limit int, query string, args ...any) ([]model.URL, error) {
+	rows, err := db.db.Pool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	urls := make([]model.URL, 0, limit)
+
+	for rows.Next() {
+		var u model.URL
+		if err := rows.Scan(&u.ID, &u.LongURL); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		urls = append(urls, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return urls, nil
+}
+
+// ListURLsQuery is filter and page of ListURLs
+type ListURLsQuery struct {
+	// BeforeID is the last ID of previous page, 0 for the first page
+	BeforeID snowflake.SID
+
+	// CreatedAfter and CreatedBefore are exclusive bound of created_at, zero means no bound.
+	// created_at is stored in second, fraction of second is dropped.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	// Host is lower case host of long url, empty means any host
+	Host string
+
+	Limit int
+}
+
+// ListedURL is url with its click count
+type ListedURL struct {
+	model.URL
+	ClickCount int64
+}
+
+// ListURLs will get at most q.Limit urls matching q with ID less than q.BeforeID, in descending ID.
+// It is newest first only if IDs grow with time (snowflake and counter strategy),
+// IDs of random and hash strategy are not time ordered.
+// Pages are keyed by ID instead of offset, so a page costs the same no matter how deep it is.
+func (db *URLShortenerDB) ListURLs(ctx context.Context, q ListURLsQuery) ([]ListedURL, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if q.BeforeID != 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, int64(q.BeforeID))
+	}
+
+	if !q.CreatedAfter.IsZero() {
+		conditions = append(conditions, "created_at > ?")
+		args = append(args, q.CreatedAfter.UTC().Format(time.DateTime))
+	}
+
+	if !q.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, q.CreatedBefore.UTC().Format(time.DateTime))
+	}
+
+	if q.Host != "" {
+		conditions = append(conditions, "host = ?")
+		args = append(args, q.Host)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, long_url, created_at, click_count
+		FROM urls
+		%s
+		ORDER BY id DESC
+		LIMIT ?;
+	`, where)
+	args = append(args, q.Limit)
+
+	rows, err := db.db.Pool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListURLs query error: %w", err)
+	}
+	defer rows.Close()
+
+	urls := make([]ListedURL, 0, q.Limit)
+
+	for rows.Next() {
+		var u ListedURL
+		if err := rows.Scan(&u.ID, &u.LongURL, &u.CreatedAt, &u.ClickCount); err != nil {
+			return nil, fmt.Errorf("ListURLs scan error: %w", err)
+		}
+		urls = append(urls, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListURLs rows error: %w", err)
+	}
+
+	return urls, nil
+}
+
+// CreateURL insert url, ID and LongURL are required.
+// CreatedAt is stored in second, now is used if it is zero.
+// database.ErrKeyConflict is wrapped if ID or LongURL already exist.
 func (db *URLShortenerDB) CreateURL(ctx context.Context, u model.URL) error {
 	if u.ID == 0 {
 		return errors.New("create URL need to provide ID")
@@ -92,12 +333,22 @@ func (db *URLShortenerDB) CreateURL(ctx context.Context, u model.URL) error {
 		return errors.New("create url need to provide longURL")
 	}
 
+	createdAt := u.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
 	query := `
-        INSERT INTO urls (id, long_url)
-        VALUES (?, ?)
+        INSERT INTO urls (id, long_url, created_at, host)
+        VALUES (?, ?, ?, ?)
     `
 
-	_, err := db.db.Pool.ExecContext(ctx, query, int64(u.ID), u.LongURL)
+	// same format as CURRENT_TIMESTAMP, so created_at of all rows compare as text
+	_, err := db.db.Pool.ExecContext(ctx, query, int64(u.ID), u.LongURL, createdAt.UTC().Format(time.DateTime), u.Host())
+
+	if database.IsKeyConflict(err) {
+		return fmt.Errorf("create url error: %w: %s", database.ErrKeyConflict, err.Error())
+	}
 
 	if err != nil {
 		return fmt.Errorf("create url error: %w", err)
@@ -105,6 +356,131 @@ func (db *URLShortenerDB) CreateURL(ctx context.Context, u model.URL) error {
 	return nil
 }
 
+// DeleteURL delete url by sid, it returns false if url not exist
+func (db *URLShortenerDB) DeleteURL(ctx context.Context, sid snowflake.SID) (bool, error) {
+	query := `
+		DELETE FROM urls
+		WHERE id = ?;
+	`
+
+	result, err := db.db.Pool.ExecContext(ctx, query, int64(sid))
+	if err != nil {
+		return false, fmt.Errorf("delete url error: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete url rows affected error: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetClickCount return click count of url, 0 if url not exist
+func (db *URLShortenerDB) GetClickCount(ctx context.Context, sid snowflake.SID) (int64, error) {
+	var count int64
+
+	query := `
+		SELECT click_count
+		FROM urls
+		WHERE id = ?;
+	`
+
+	err := db.db.Pool.QueryRowContext(ctx, query, int64(sid)).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("GetClickCount scan error: %w", err)
+	}
+
+	return count, nil
+}
+
+// AddClicks add clicks of each url in one transaction,
+// url not exist (ex: deleted) is ignored.
+func (db *URLShortenerDB) AddClicks(ctx context.Context, clicks map[snowflake.SID]int64) error {
+	query := `
+		UPDATE urls
+		SET click_count = click_count + ?
+		WHERE id = ?;
+	`
+
+	return db.db.InTx(ctx, nil, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("AddClicks prepare error: %w", err)
+		}
+		defer stmt.Close()
+
+		for sid, n := range clicks {
+			if _, err := stmt.ExecContext(ctx, n, int64(sid)); err != nil {
+				return fmt.Errorf("AddClicks exec error: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// NextCounter increase the code counter and return the new value, it starts from 1
+func (db *URLShortenerDB) NextCounter(ctx context.Context) (int64, error) {
+	var value int64
+
+	query := `
+		INSERT INTO code_counter (name, value)
+		VALUES ('urls', 1)
+		ON CONFLICT (name) DO UPDATE SET value = value + 1
+		RETURNING value;
+	`
+
+	if err := db.db.Pool.QueryRowContext(ctx, query).Scan(&value); err != nil {
+		return 0, fmt.Errorf("NextCounter scan error: %w", err)
+	}
+
+	return value, nil
+}
+
+// GetHighWaterMark return the largest timestamp (unix ms) node may have issued,
+// 0 if node never saved one
+func (db *URLShortenerDB) GetHighWaterMark(ctx context.Context, nodeID int64) (int64, error) {
+	var mark int64
+
+	query := `
+		SELECT timestamp_ms
+		FROM id_high_water_marks
+		WHERE node_id = ?;
+	`
+
+	err := db.db.Pool.QueryRowContext(ctx, query, nodeID).Scan(&mark)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("GetHighWaterMark scan error: %w", err)
+	}
+
+	return mark, nil
+}
+
+// SaveHighWaterMark save mark of node, mark never moves backwards
+func (db *URLShortenerDB) SaveHighWaterMark(ctx context.Context, nodeID int64, unixMilli int64) error {
+	query := `
+		INSERT INTO id_high_water_marks (node_id, timestamp_ms)
+		VALUES (?, ?)
+		ON CONFLICT (node_id) DO UPDATE
+		SET timestamp_ms = MAX(timestamp_ms, excluded.timestamp_ms);
+	`
+
+	if _, err := db.db.Pool.ExecContext(ctx, query, nodeID, unixMilli); err != nil {
+		return fmt.Errorf("save high water mark error: %w", err)
+	}
+
+	return nil
+}
+
 // GetFirstByID will get first url by sid
 // return URL in zero value if not found
 // func (db *URLShortenerDB) GetFirstByID(ctx context.Context, sid snowflake.SID) (model.URL, error) {
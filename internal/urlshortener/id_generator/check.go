@@ -0,0 +1,43 @@
+package idgenerator
+
+// checkChar return the Luhn mod N check character of code.
+// It catches every single character typo and most swaps of adjacent characters.
+// ok is false if code has character outside alphabet.
+func checkChar(code string, alphabet string) (byte, bool) {
+	n := len(alphabet)
+	sum := 0
+	factor := 2
+
+	for i := len(code) - 1; i >= 0; i-- {
+		codePoint := indexOf(alphabet, code[i])
+		if codePoint < 0 {
+			return 0, false
+		}
+
+		addend := factor * codePoint
+		sum += addend/n + addend%n
+
+		factor = 3 - factor
+	}
+
+	return alphabet[(n-sum%n)%n], true
+}
+
+// validCheckChar report whether the last character of code is its check character
+func validCheckChar(code string, alphabet string) bool {
+	if len(code) < 2 {
+		return false
+	}
+
+	check, ok := checkChar(code[:len(code)-1], alphabet)
+	return ok && check == code[len(code)-1]
+}
+
+func indexOf(alphabet string, ch byte) int {
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == ch {
+			return i
+		}
+	}
+	return -1
+}
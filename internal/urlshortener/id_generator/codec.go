@@ -0,0 +1,199 @@
+package idgenerator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/TinyMurky/snowflake"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+// codeVersionLegacy is plain code of ID without prefix.
+// Versioned code starts with the zero character of alphabet ("0" of base62, "1" of base58),
+// positive ID never starts with it, so code without it is a legacy code.
+const codeVersionLegacy byte = 0
+
+const (
+	// codeVersionObfuscated is code of ID permuted by ID_GEN_OBFUSCATION_KEY
+	codeVersionObfuscated byte = '1'
+	// codeVersionChecked is code of ID with check character
+	codeVersionChecked byte = '2'
+	// codeVersionObfuscatedChecked is obfuscated code with check character
+	codeVersionObfuscatedChecked byte = '3'
+)
+
+// ErrInvalidCode is returned when code can not be decoded
+var ErrInvalidCode = errors.New("idgenerator: invalid code")
+
+// ErrCheckCharMismatch is returned when check character of code is wrong,
+// code is mistyped and can not exist.
+var ErrCheckCharMismatch = fmt.Errorf("%w: check character mismatch", ErrInvalidCode)
+
+// Codec convert between ID and short code
+type Codec interface {
+	Encode(id snowflake.SID) string
+	Decode(code string) (snowflake.SID, error)
+}
+
+// NewCodec create codec of config.
+//
+// Without ID_GEN_OBFUSCATION_KEY and ID_GEN_CHECK_CHAR_ENABLED codes are plain ID encoded by ID_GEN_ALPHABET.
+// Otherwise new codes are zero character + version + payload (+ check character), see codeVersion*.
+//
+// Codes of every version that can be decoded with the config are accepted,
+// so links shared before a version change keep resolving.
+// When obfuscation is on, plain codes are accepted only for ID not larger than
+// ID_GEN_LEGACY_CODE_MAX_ID, which must be set (negative rejects every plain code).
+// Changing ID_GEN_ALPHABET breaks every code shared before.
+func NewCodec(cfg *urlshortenerconfig.Config) (Codec, error) {
+	idCfg := cfg.IDGenerator
+
+	alphabet, err := model.ParseAlphabet(idCfg.Alphabet)
+	if err != nil {
+		return nil, fmt.Errorf("ID_GEN_ALPHABET: %w", err)
+	}
+
+	plain := alphabetCodec{alphabet: alphabet}
+
+	c := &versionedCodec{
+		alphabet: alphabet,
+		current:  codeVersionLegacy,
+		versions: map[byte]codeVersion{
+			codeVersionChecked: {codec: plain, check: true},
+		},
+		legacyMaxID: math.MaxInt64,
+	}
+
+	if idCfg.CheckCharEnabled {
+		c.current = codeVersionChecked
+	}
+
+	if idCfg.ObfuscationKey != "" {
+		if idCfg.LegacyCodeMaxID == 0 {
+			return nil, errors.New("ID_GEN_LEGACY_CODE_MAX_ID is required with ID_GEN_OBFUSCATION_KEY, " +
+				"set it to the largest ID before obfuscation, or -1 if there is none")
+		}
+
+		obfuscated := &obfuscatedCodec{plain: plain, feistel: newFeistel(idCfg.ObfuscationKey)}
+		c.versions[codeVersionObfuscated] = codeVersion{codec: obfuscated}
+		c.versions[codeVersionObfuscatedChecked] = codeVersion{codec: obfuscated, check: true}
+		c.legacyMaxID = snowflake.SID(idCfg.LegacyCodeMaxID)
+
+		c.current = codeVersionObfuscated
+		if idCfg.CheckCharEnabled {
+			c.current = codeVersionObfuscatedChecked
+		}
+	}
+
+	return c, nil
+}
+
+// alphabetCodec encode ID as plain code of alphabet, it is the legacy code
+type alphabetCodec struct {
+	alphabet model.Alphabet
+}
+
+// Encode ID into plain code
+func (c alphabetCodec) Encode(id snowflake.SID) string {
+	return c.alphabet.Encode(id)
+}
+
+// Decode plain code into ID
+func (c alphabetCodec) Decode(code string) (snowflake.SID, error) {
+	id, err := c.alphabet.Decode(code)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidCode, err)
+	}
+	return id, nil
+}
+
+// obfuscatedCodec hide order of IDs by keyed permutation,
+// so codes can not be enumerated without the key.
+type obfuscatedCodec struct {
+	plain   alphabetCodec
+	feistel *feistel
+}
+
+func (c *obfuscatedCodec) Encode(id snowflake.SID) string {
+	return c.plain.Encode(snowflake.SID(c.feistel.Permute(uint64(id))))
+}
+
+func (c *obfuscatedCodec) Decode(code string) (snowflake.SID, error) {
+	v, err := c.plain.Decode(code)
+	if err != nil {
+		return 0, err
+	}
+	return snowflake.SID(c.feistel.Unpermute(uint64(v))), nil
+}
+
+// codeVersion is how payload of a version is encoded
+type codeVersion struct {
+	codec Codec
+	// check append check character of the whole code
+	check bool
+}
+
+// versionedCodec encode with current version and decode every known version.
+// Versioned code is zero character + version + payload (+ check character).
+type versionedCodec struct {
+	alphabet model.Alphabet
+	current  byte
+	versions map[byte]codeVersion
+	// legacyMaxID is the largest ID of accepted plain code
+	legacyMaxID snowflake.SID
+}
+
+func (c *versionedCodec) Encode(id snowflake.SID) string {
+	if c.current == codeVersionLegacy {
+		return c.alphabet.Encode(id)
+	}
+
+	version := c.versions[c.current]
+	code := string([]byte{c.alphabet.Zero(), c.current}) + version.codec.Encode(id)
+
+	if version.check {
+		check, _ := checkChar(code, c.alphabet.Chars())
+		code += string(check)
+	}
+
+	return code
+}
+
+func (c *versionedCodec) Decode(code string) (snowflake.SID, error) {
+	// check character is computed on normalized code
+	code = c.alphabet.Normalize(code)
+
+	if len(code) < 2 || code[0] != c.alphabet.Zero() {
+		return c.decodeLegacy(code)
+	}
+
+	version, ok := c.versions[code[1]]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown version %q", ErrInvalidCode, code[1])
+	}
+
+	if version.check {
+		if !validCheckChar(code, c.alphabet.Chars()) {
+			return 0, ErrCheckCharMismatch
+		}
+		code = code[:len(code)-1]
+	}
+
+	return version.codec.Decode(code[2:])
+}
+
+func (c *versionedCodec) decodeLegacy(code string) (snowflake.SID, error) {
+	id, err := alphabetCodec{alphabet: c.alphabet}.Decode(code)
+	if err != nil {
+		return 0, err
+	}
+
+	if id > c.legacyMaxID {
+		return 0, fmt.Errorf("%w: legacy code of ID after ID_GEN_LEGACY_CODE_MAX_ID", ErrInvalidCode)
+	}
+
+	return id, nil
+}
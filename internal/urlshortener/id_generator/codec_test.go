@@ -0,0 +1,180 @@
+package idgenerator
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/TinyMurky/snowflake"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+func newTestCodec(t *testing.T, cfg *urlshortenerconfig.Config) Codec {
+	t.Helper()
+
+	codec, err := NewCodec(cfg)
+	if err != nil {
+		t.Fatalf("NewCodec: %v", err)
+	}
+	return codec
+}
+
+func TestObfuscatedCodec(t *testing.T) {
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.ObfuscationKey = "secret"
+	cfg.IDGenerator.LegacyCodeMaxID = -1
+	codec := newTestCodec(t, cfg)
+
+	ids := []snowflake.SID{1, 2, 3, 12345, 1 << 40, math.MaxInt64}
+	seen := map[string]bool{}
+
+	for _, id := range ids {
+		code := codec.Encode(id)
+		if !strings.HasPrefix(code, "01") {
+			t.Errorf("code %q of %d should have version prefix", code, id)
+		}
+
+		if code[2:] == id.Base62() {
+			t.Errorf("code %q of %d is not obfuscated", code, id)
+		}
+
+		if seen[code] {
+			t.Errorf("code %q is duplicated", code)
+		}
+		seen[code] = true
+
+		decoded, err := codec.Decode(code)
+		if err != nil || decoded != id {
+			t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, id)
+		}
+	}
+
+	// codes of another key decode into different ID
+	cfg.IDGenerator.ObfuscationKey = "other"
+	if decoded, _ := newTestCodec(t, cfg).Decode(codec.Encode(12345)); decoded == 12345 {
+		t.Error("code should depend on key")
+	}
+}
+
+func TestLegacyCode(t *testing.T) {
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.ObfuscationKey = "secret"
+	cfg.IDGenerator.LegacyCodeMaxID = 1000
+	codec := newTestCodec(t, cfg)
+
+	legacy := snowflake.SID(999).Base62()
+	if id, err := codec.Decode(legacy); err != nil || id != 999 {
+		t.Errorf("legacy code %q should decode to 999, got %d, %v", legacy, id, err)
+	}
+
+	if _, err := codec.Decode(snowflake.SID(1001).Base62()); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("legacy code after max ID should be invalid, got %v", err)
+	}
+
+	if _, err := codec.Decode("09abc"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("unknown version should be invalid, got %v", err)
+	}
+
+	cfg.IDGenerator.LegacyCodeMaxID = -1
+	if _, err := newTestCodec(t, cfg).Decode(legacy); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("legacy code should be invalid when max ID is negative, got %v", err)
+	}
+
+	cfg.IDGenerator.LegacyCodeMaxID = 0
+	if _, err := NewCodec(cfg); err == nil {
+		t.Error("obfuscation without ID_GEN_LEGACY_CODE_MAX_ID should fail")
+	}
+}
+
+func TestCheckedCodec(t *testing.T) {
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.CheckCharEnabled = true
+	codec := newTestCodec(t, cfg)
+
+	id := snowflake.SID(123456789)
+	code := codec.Encode(id)
+
+	if !strings.HasPrefix(code, "02") || len(code) != len(id.Base62())+3 {
+		t.Fatalf("unexpected code %q", code)
+	}
+
+	if decoded, err := codec.Decode(code); err != nil || decoded != id {
+		t.Fatalf("Decode(%q) = %d, %v, want %d", code, decoded, err, id)
+	}
+
+	base62Alphabet := model.AlphabetBase62.Chars()
+
+	// every single character typo after the prefix is caught,
+	// typo of the prefix itself reads as a legacy code of another ID
+	for i := 1; i < len(code); i++ {
+		for j := 0; j < len(base62Alphabet); j++ {
+			if base62Alphabet[j] == code[i] {
+				continue
+			}
+
+			typo := code[:i] + string(base62Alphabet[j]) + code[i+1:]
+			if _, err := codec.Decode(typo); err == nil {
+				t.Errorf("typo %q of %q is accepted", typo, code)
+			}
+		}
+	}
+
+	// plain code still works
+	if decoded, err := codec.Decode(id.Base62()); err != nil || decoded != id {
+		t.Errorf("legacy code should decode, got %d, %v", decoded, err)
+	}
+}
+
+func TestBase32Codec(t *testing.T) {
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.Alphabet = "base32"
+	cfg.IDGenerator.CheckCharEnabled = true
+	codec := newTestCodec(t, cfg)
+
+	id := snowflake.SID(123456789)
+	code := codec.Encode(id)
+
+	if strings.ContainsAny(code, "ILOUilou") || code != strings.ToUpper(code) {
+		t.Fatalf("code %q has character outside Crockford base32", code)
+	}
+
+	// read aloud or typed in lower case with look-alike characters
+	typed := strings.NewReplacer("0", "o", "1", "l").Replace(strings.ToLower(code))
+	if decoded, err := codec.Decode(typed); err != nil || decoded != id {
+		t.Errorf("Decode(%q) = %d, %v, want %d", typed, decoded, err, id)
+	}
+
+	if _, err := NewCodec(&urlshortenerconfig.Config{IDGenerator: urlshortenerconfig.IDGeneratorConfig{Alphabet: "base64"}}); err == nil {
+		t.Error("unknown alphabet should fail")
+	}
+}
+
+func TestBase58Codec(t *testing.T) {
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.Alphabet = "base58"
+	cfg.IDGenerator.ObfuscationKey = "secret"
+	cfg.IDGenerator.LegacyCodeMaxID = 1000
+	cfg.IDGenerator.CheckCharEnabled = true
+	codec := newTestCodec(t, cfg)
+
+	for _, id := range []snowflake.SID{1, 58, 123456789, math.MaxInt64} {
+		code := codec.Encode(id)
+
+		// "1" is zero of base58, so it is the version prefix
+		if !strings.HasPrefix(code, "13") || strings.ContainsAny(code, "0OIl") {
+			t.Errorf("unexpected code %q of %d", code, id)
+		}
+
+		if decoded, err := codec.Decode(code); err != nil || decoded != id {
+			t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, id)
+		}
+	}
+
+	// plain base58 code is legacy code
+	if decoded, err := codec.Decode(snowflake.SID(999).Base58()); err != nil || decoded != 999 {
+		t.Errorf("legacy code should decode, got %d, %v", decoded, err)
+	}
+}
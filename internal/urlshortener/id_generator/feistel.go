@@ -0,0 +1,75 @@
+package idgenerator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// feistelRounds is the number of rounds, 4 is enough for a pseudorandom permutation
+// and more rounds make it harder to guess the key from known pairs
+const feistelRounds = 8
+
+// feistel is a keyed permutation over 63 bits positive int64.
+//
+// It is a balanced Feistel network over 64 bits, values that fall outside
+// 63 bits are permuted again (cycle walking), so the result is still a positive int64.
+type feistel struct {
+	roundKeys [feistelRounds][]byte
+}
+
+func newFeistel(key string) *feistel {
+	f := &feistel{}
+	for i := range f.roundKeys {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte{byte(i)})
+		f.roundKeys[i] = mac.Sum(nil)
+	}
+	return f
+}
+
+// Permute map v to another value in [0, 2^63)
+func (f *feistel) Permute(v uint64) uint64 {
+	for {
+		v = f.forward(v)
+		if v>>63 == 0 {
+			return v
+		}
+	}
+}
+
+// Unpermute is inverse of Permute
+func (f *feistel) Unpermute(v uint64) uint64 {
+	for {
+		v = f.backward(v)
+		if v>>63 == 0 {
+			return v
+		}
+	}
+}
+
+func (f *feistel) forward(v uint64) uint64 {
+	l, r := uint32(v>>32), uint32(v)
+	for i := range feistelRounds {
+		l, r = r, l^f.round(i, r)
+	}
+	return uint64(l)<<32 | uint64(r)
+}
+
+func (f *feistel) backward(v uint64) uint64 {
+	l, r := uint32(v>>32), uint32(v)
+	for i := feistelRounds - 1; i >= 0; i-- {
+		l, r = r^f.round(i, l), l
+	}
+	return uint64(l)<<32 | uint64(r)
+}
+
+func (f *feistel) round(i int, half uint32) uint32 {
+	mac := hmac.New(sha256.New, f.roundKeys[i])
+
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], half)
+	mac.Write(buf[:])
+
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
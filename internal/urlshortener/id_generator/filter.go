@@ -0,0 +1,139 @@
+package idgenerator
+
+import (
+	"bufio"
+	"context"
+	"expvar"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TinyMurky/snowflake"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+)
+
+// maxBlockedCodeSkips is how many blocked codes in a row are skipped before giving up,
+// blocked codes are rare so hitting it means the word list blocks almost everything.
+const maxBlockedCodeSkips = 16
+
+// blockedCodeSkipped count generated codes skipped by CodeFilter
+var blockedCodeSkipped = expvar.NewInt("idgenerator_blocked_code_skipped_total")
+
+// leetReplacer turn digits that look like letters back into letters,
+// so "5h1t" is matched by "shit".
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t")
+
+// CodeFilter refuse codes that are reserved names or contain blocked words.
+//
+// Reserved names (ID_GEN_RESERVED_CODES) match the whole code case-insensitively,
+// they are names of routes and should never be a code or an alias.
+// Blocked words (ID_GEN_BLOCKED_WORDS and ID_GEN_BLOCKED_WORDS_FILE) match any part of
+// generated codes case-insensitively, with digits read as look-alike letters.
+type CodeFilter struct {
+	reserved map[string]struct{}
+	words    []string
+}
+
+// NewCodeFilter create filter of config, error is returned if words file can not be read
+func NewCodeFilter(cfg *urlshortenerconfig.Config) (*CodeFilter, error) {
+	idCfg := cfg.IDGenerator
+
+	f := &CodeFilter{
+		reserved: make(map[string]struct{}, len(idCfg.ReservedCodes)),
+	}
+
+	for _, name := range idCfg.ReservedCodes {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			f.reserved[name] = struct{}{}
+		}
+	}
+
+	f.addWords(idCfg.BlockedWords)
+
+	if idCfg.BlockedWordsFile != "" {
+		words, err := readWordsFile(idCfg.BlockedWordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("ID_GEN_BLOCKED_WORDS_FILE: %w", err)
+		}
+		f.addWords(words)
+	}
+
+	return f, nil
+}
+
+func (f *CodeFilter) addWords(words []string) {
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			f.words = append(f.words, word)
+		}
+	}
+}
+
+// Allowed report whether generated code is neither reserved nor contains a blocked word
+func (f *CodeFilter) Allowed(code string) bool {
+	lower := strings.ToLower(code)
+
+	if _, ok := f.reserved[lower]; ok {
+		return false
+	}
+	leet := leetReplacer.Replace(lower)
+
+	for _, word := range f.words {
+		if strings.Contains(lower, word) || strings.Contains(leet, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nextAllowedID call next until code of ID is allowed by filter,
+// it is used by strategies whose next ID is independent of the skipped one.
+func nextAllowedID(
+	ctx context.Context,
+	filter *CodeFilter,
+	codec Codec,
+	next func(ctx context.Context) (snowflake.SID, error),
+) (snowflake.SID, error) {
+	for range maxBlockedCodeSkips {
+		id, err := next(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if filter.Allowed(codec.Encode(id)) {
+			return id, nil
+		}
+
+		blockedCodeSkipped.Add(1)
+	}
+
+	return 0, fmt.Errorf("%w: %d codes in a row are blocked", ErrNoFreeCode, maxBlockedCodeSkips)
+}
+
+// readWordsFile read one word per line, empty lines and lines start with "#" are ignored
+func readWordsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
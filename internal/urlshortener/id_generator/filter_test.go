@@ -0,0 +1,86 @@
+package idgenerator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TinyMurky/snowflake"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+func TestCodeFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# more words\nbadword\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.ReservedCodes = []string{"api", " Admin "}
+	cfg.IDGenerator.BlockedWords = []string{"shit"}
+	cfg.IDGenerator.BlockedWordsFile = path
+
+	filter, err := NewCodeFilter(cfg)
+	if err != nil {
+		t.Fatalf("NewCodeFilter: %v", err)
+	}
+
+	tests := []struct {
+		code    string
+		allowed bool
+	}{
+		{"aB3xY9z", true},
+		{"API", false},
+		{"admin", false},
+		{"apiX", true},
+		{"xxSHITxx", false},
+		{"x5h1tx", false},
+		{"0BADWORD", false},
+	}
+
+	for _, tt := range tests {
+		if got := filter.Allowed(tt.code); got != tt.allowed {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.code, got, tt.allowed)
+		}
+	}
+
+	cfg.IDGenerator.BlockedWordsFile = filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := NewCodeFilter(cfg); err == nil {
+		t.Error("missing words file should fail")
+	}
+}
+
+func TestStrategySkipBlockedCode(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.Strategy = StrategyCounter
+	// codes of counter 1 and 2
+	cfg.IDGenerator.ReservedCodes = []string{"1", "2"}
+
+	store := &fakeStore{urls: map[snowflake.SID]string{}}
+	strategy, err := NewStrategy(cfg, nil, store)
+	if err != nil {
+		t.Fatalf("NewStrategy: %v", err)
+	}
+
+	id, err := strategy.NextID(ctx, "https://example.com")
+	if err != nil || id != 3 {
+		t.Fatalf("NextID = %d, %v, want 3", id, err)
+	}
+
+	// every code is blocked
+	cfg.IDGenerator.BlockedWords = strings.Split(model.AlphabetBase62.Chars(), "")
+	if strategy, err = NewStrategy(cfg, nil, store); err != nil {
+		t.Fatalf("NewStrategy: %v", err)
+	}
+
+	if _, err := strategy.NextID(ctx, "https://example.com"); !errors.Is(err, ErrNoFreeCode) {
+		t.Errorf("NextID should fail with ErrNoFreeCode, got %v", err)
+	}
+}
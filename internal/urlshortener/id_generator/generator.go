@@ -2,31 +2,121 @@
 package idgenerator
 
 import (
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/TinyMurky/snowflake"
 
 	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+	"github.com/TinyMurky/tinyurl/pkg/nodelease"
 )
 
 const epochTimeFormat = "2006-01-02"
 
+// timestampShift is the bits of node and step below timestamp of snowflake ID
+const timestampShift = 22
+
+// stepBits is the bits of sequence in the same millisecond, node ID is above it
+const stepBits = 12
+
+// ErrClockMovedBackwards is returned when clock is behind the last issued ID
+// longer than ID_GEN_MAX_CLOCK_BACKWARD_WAIT
+var ErrClockMovedBackwards = errors.New("idgenerator: clock moved backwards")
+
+// metrics of clock skew, served by /api/v1/admin/debug/vars
+var (
+	clockBackwardTotal  = expvar.NewInt("idgenerator_clock_backward_total")
+	clockSkewLastMilli  = expvar.NewInt("idgenerator_clock_skew_last_ms")
+	clockSkewMaxMilli   = expvar.NewInt("idgenerator_clock_skew_max_ms")
+	clockBackwardFailed = expvar.NewInt("idgenerator_clock_backward_failed_total")
+)
+
+// HighWaterMarkStore persist the largest timestamp (unix ms) a node may have issued,
+// it is implemented by database.URLShortenerDB
+type HighWaterMarkStore interface {
+	GetHighWaterMark(ctx context.Context, nodeID int64) (int64, error)
+	SaveHighWaterMark(ctx context.Context, nodeID int64, unixMilli int64) error
+}
+
 // Generator will generate snowflake ID
 type Generator struct {
 	generator *snowflake.Generator
 	nodeID    int64
+
+	// lease is nil if node ID is read from config
+	lease *nodelease.Lease
+
+	// marks is nil if high-water mark is not persisted
+	marks   HighWaterMarkStore
+	ahead   time.Duration
+	maxWait time.Duration
+	now     func() time.Time
+
+	mu sync.Mutex
+	// lastMilli is unix ms of the last ID issued by this run
+	lastMilli int64
+	// lastRunMilli is the high-water mark of last run, it issued IDs up to it
+	// and at least up to lastRunMilli - ahead
+	lastRunMilli int64
+	// reservedMilli is the persisted high-water mark, IDs before it can be issued without saving
+	reservedMilli int64
+	loaded        bool
 }
 
-// NewGenerator will create a new snowflakeID generator
-func NewGenerator(cfg *urlshortenerconfig.Config) (*Generator, error) {
+// NewGenerator will create a new snowflakeID generator.
+// If lease is not nil, its node ID is used instead of ID_GEN_NODE_ID.
+// If marks is not nil, high-water mark of issued timestamp is persisted,
+// so clock moved backwards across restart is detected as well.
+func NewGenerator(cfg *urlshortenerconfig.Config, lease *nodelease.Lease, marks HighWaterMarkStore) (*Generator, error) {
+	if marks != nil && cfg.IDGenerator.HighWaterMarkAhead > cfg.IDGenerator.MaxClockBackwardWait {
+		return nil, fmt.Errorf("ID_GEN_HIGH_WATER_MARK_AHEAD %s must not be longer than ID_GEN_MAX_CLOCK_BACKWARD_WAIT %s, "+
+			"otherwise a restart can not wait out the mark of last run",
+			cfg.IDGenerator.HighWaterMarkAhead, cfg.IDGenerator.MaxClockBackwardWait)
+	}
+
 	nodeID := cfg.IDGenerator.NodeID
+	if lease != nil {
+		nodeID = lease.NodeID()
+	}
+
+	epochTime, err := ParseEpoch(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	generator, err := snowflake.NewGenerator(
+		snowflake.WithEpoch(epochTime),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("new snowflake generator error: %w", err)
+	}
+
+	return &Generator{
+		generator: generator,
+		nodeID:    nodeID,
+		lease:     lease,
+		marks:     marks,
+		ahead:     cfg.IDGenerator.HighWaterMarkAhead,
+		maxWait:   cfg.IDGenerator.MaxClockBackwardWait,
+		now:       time.Now,
+	}, nil
+}
+
+// ParseEpoch return ID_GEN_EPOCH_TIME_START_FROM as UTC midnight,
+// timestamp of snowflake ID is milliseconds since it.
+func ParseEpoch(cfg *urlshortenerconfig.Config) (time.Time, error) {
 	epochTimeStartFrom := cfg.IDGenerator.EpochTimeStartFrom
 
 	epochStartDate, err := time.Parse(epochTimeFormat, epochTimeStartFrom)
 
 	if err != nil {
-		return nil, fmt.Errorf("invalid epoch time format, required: %s, got: %s", epochTimeFormat, epochTimeStartFrom)
+		return time.Time{}, fmt.Errorf("invalid epoch time format, required: %s, got: %s", epochTimeFormat, epochTimeStartFrom)
 	}
 
 	epochTime := time.Date(
@@ -40,21 +130,156 @@ func NewGenerator(cfg *urlshortenerconfig.Config) (*Generator, error) {
 		time.UTC,
 	)
 
-	generator, err := snowflake.NewGenerator(
-		snowflake.WithEpoch(epochTime),
-	)
+	return epochTime, nil
+}
+
+// Parts is what a snowflake ID is made of
+type Parts struct {
+	Time     time.Time
+	NodeID   int64
+	Sequence int64
+}
+
+// Decompose split snowflake ID into time, node ID and sequence.
+// ID of other strategies is not a snowflake ID, its parts mean nothing.
+func Decompose(id snowflake.SID, epoch time.Time) Parts {
+	v := int64(id)
+
+	return Parts{
+		Time:     epoch.Add(time.Duration(v>>timestampShift) * time.Millisecond).UTC(),
+		NodeID:   (v >> stepBits) & (1<<(timestampShift-stepBits) - 1),
+		Sequence: v & (1<<stepBits - 1),
+	}
+}
+
+// NextID will get 1 snowflakeID.
+// nodelease.ErrLeaseLost is returned if node ID is leased and the lease is lost,
+// because another instance may be using the same node ID.
+//
+// If clock is behind the last issued ID, it waits up to ID_GEN_MAX_CLOCK_BACKWARD_WAIT
+// for clock to catch up, or returns ErrClockMovedBackwards.
+func (g *Generator) NextID(ctx context.Context) (snowflake.SID, error) {
+	if g.lease != nil {
+		if err := g.lease.Valid(); err != nil {
+			return 0, fmt.Errorf("node %d: %w", g.nodeID, err)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
+	if err := g.loadHighWaterMark(ctx); err != nil {
+		return 0, err
+	}
+
+	nowMilli, err := g.waitClock(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("new snowflake generator error: %w", err)
+		return 0, err
 	}
 
-	return &Generator{
-		generator: generator,
-		nodeID:    nodeID,
-	}, nil
+	if err := g.reserve(ctx, nowMilli); err != nil {
+		return 0, err
+	}
+
+	sid, err := g.generator.NextID(g.nodeID)
+	if err != nil {
+		return 0, err
+	}
+
+	g.lastMilli = max(g.lastMilli, g.generator.Epoch.UnixMilli()+int64(sid)>>timestampShift)
+
+	return sid, nil
+}
+
+// loadHighWaterMark load mark of last run once
+func (g *Generator) loadHighWaterMark(ctx context.Context) error {
+	if g.loaded || g.marks == nil {
+		return nil
+	}
+
+	mark, err := g.marks.GetHighWaterMark(ctx, g.nodeID)
+	if err != nil {
+		return fmt.Errorf("GetHighWaterMark of node %d: %w", g.nodeID, err)
+	}
+
+	g.lastRunMilli = mark
+	g.reservedMilli = mark
+	g.loaded = true
+
+	return nil
+}
+
+// waitClock return current unix ms once it is not behind lastMilli and mark of last run.
+//
+// Clock inside the reserved window of last run is a normal restart, it is waited out silently.
+// Only clock behind an ID surely issued is skew, it is recorded in metrics.
+func (g *Generator) waitClock(ctx context.Context) (int64, error) {
+	nowMilli := g.now().UnixMilli()
+	floorMilli := max(g.lastMilli, g.lastRunMilli)
+	if nowMilli >= floorMilli {
+		return nowMilli, nil
+	}
+
+	wait := time.Duration(floorMilli-nowMilli) * time.Millisecond
+
+	issuedMilli := g.lastMilli
+	if g.lastRunMilli > 0 {
+		issuedMilli = max(issuedMilli, g.lastRunMilli-g.ahead.Milliseconds())
+	}
+	skew := time.Duration(issuedMilli-nowMilli) * time.Millisecond
+
+	logger := logging.FromContext(ctx).Named("idgenerator")
+
+	if skew > 0 {
+		recordClockSkew(skew)
+
+		// wait is at most skew + ahead, fail only if clock is really behind
+		if wait > g.maxWait {
+			clockBackwardFailed.Add(1)
+			logger.Errorw("clock moved backwards, refuse to generate ID",
+				"node_id", g.nodeID, "skew", skew.String(), "max_wait", g.maxWait.String())
+			return 0, fmt.Errorf("%w by %s on node %d", ErrClockMovedBackwards, skew, g.nodeID)
+		}
+
+		logger.Warnw("clock moved backwards, wait for it", "node_id", g.nodeID, "skew", skew.String())
+	} else {
+		// ahead is not longer than maxWait, see NewGenerator
+		logger.Infow("wait for high-water mark of last run", "node_id", g.nodeID, "wait", wait.String())
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-timer.C:
+	}
+
+	return max(g.now().UnixMilli(), floorMilli), nil
 }
 
-// NextID will get 1 snowflakeID
-func (g *Generator) NextID() (snowflake.SID, error) {
-	return g.generator.NextID(g.nodeID)
+// reserve persist high-water mark ahead of nowMilli when it is used up,
+// so at most one write happens per ID_GEN_HIGH_WATER_MARK_AHEAD.
+func (g *Generator) reserve(ctx context.Context, nowMilli int64) error {
+	if g.marks == nil || nowMilli < g.reservedMilli {
+		return nil
+	}
+
+	mark := nowMilli + g.ahead.Milliseconds()
+	if err := g.marks.SaveHighWaterMark(ctx, g.nodeID, mark); err != nil {
+		return fmt.Errorf("SaveHighWaterMark of node %d: %w", g.nodeID, err)
+	}
+
+	g.reservedMilli = mark
+	return nil
+}
+
+func recordClockSkew(skew time.Duration) {
+	clockBackwardTotal.Add(1)
+	clockSkewLastMilli.Set(skew.Milliseconds())
+
+	if skew.Milliseconds() > clockSkewMaxMilli.Value() {
+		clockSkewMaxMilli.Set(skew.Milliseconds())
+	}
 }
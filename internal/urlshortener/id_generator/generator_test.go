@@ -0,0 +1,142 @@
+package idgenerator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+)
+
+func newTestGenerator(t *testing.T, marks HighWaterMarkStore) *Generator {
+	t.Helper()
+
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.EpochTimeStartFrom = "2025-12-14"
+	cfg.IDGenerator.MaxClockBackwardWait = 50 * time.Millisecond
+	cfg.IDGenerator.HighWaterMarkAhead = 40 * time.Millisecond
+
+	g, err := NewGenerator(cfg, nil, marks)
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	return g
+}
+
+func TestClockMovedBackwards(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGenerator(t, nil)
+
+	if _, err := g.NextID(ctx); err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	// small step back is waited
+	g.now = func() time.Time { return time.Now().Add(-20 * time.Millisecond) }
+	before := clockBackwardTotal.Value()
+
+	if _, err := g.NextID(ctx); err != nil {
+		t.Fatalf("NextID should wait for small skew: %v", err)
+	}
+
+	if clockBackwardTotal.Value() != before+1 {
+		t.Error("skew should be counted")
+	}
+
+	// large step back fails
+	g.now = func() time.Time { return time.Now().Add(-time.Minute) }
+
+	if _, err := g.NextID(ctx); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Errorf("expect ErrClockMovedBackwards, got %v", err)
+	}
+}
+
+func TestHighWaterMark(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{}
+
+	g := newTestGenerator(t, store)
+	if _, err := g.NextID(ctx); err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	mark := store.marks[g.nodeID]
+	if mark < time.Now().UnixMilli() {
+		t.Fatalf("mark %d should be ahead of clock", mark)
+	}
+
+	// restart with clock behind the mark of last run
+	g = newTestGenerator(t, store)
+	g.now = func() time.Time { return time.UnixMilli(mark).Add(-time.Minute) }
+
+	if _, err := g.NextID(ctx); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Errorf("expect ErrClockMovedBackwards after restart, got %v", err)
+	}
+}
+
+func TestHighWaterMarkRestart(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{}
+
+	g := newTestGenerator(t, store)
+	if _, err := g.NextID(ctx); err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+	mark := store.marks[g.nodeID]
+
+	// normal restart right away, clock is inside the window reserved by last run
+	g = newTestGenerator(t, store)
+	before := clockBackwardTotal.Value()
+
+	id, err := g.NextID(ctx)
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	if clockBackwardTotal.Value() != before {
+		t.Error("waiting out reserved window should not be counted as skew")
+	}
+
+	if issued := Decompose(id, g.generator.Epoch).Time.UnixMilli(); issued < mark {
+		t.Errorf("ID at %d is before mark of last run %d", issued, mark)
+	}
+}
+
+func TestNewGeneratorAheadLongerThanMaxWait(t *testing.T) {
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.EpochTimeStartFrom = "2025-12-14"
+	cfg.IDGenerator.MaxClockBackwardWait = time.Second
+	cfg.IDGenerator.HighWaterMarkAhead = 2 * time.Second
+
+	if _, err := NewGenerator(cfg, nil, &fakeStore{}); err == nil {
+		t.Error("expect error when ahead is longer than max wait")
+	}
+
+	if _, err := NewGenerator(cfg, nil, nil); err != nil {
+		t.Errorf("ahead is not used without marks, got %v", err)
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGenerator(t, nil)
+	g.nodeID = 7
+
+	before := time.Now().Truncate(time.Millisecond)
+
+	id, err := g.NextID(ctx)
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	parts := Decompose(id, g.generator.Epoch)
+
+	if parts.NodeID != 7 || parts.Sequence < 0 || parts.Sequence >= 1<<stepBits {
+		t.Errorf("unexpected parts %+v", parts)
+	}
+
+	if parts.Time.Before(before) || parts.Time.After(time.Now()) {
+		t.Errorf("time %s is not when ID is generated", parts.Time)
+	}
+}
@@ -0,0 +1,148 @@
+package idgenerator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TinyMurky/snowflake"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/pkg/nodelease"
+)
+
+const (
+	// StrategySnowflake use snowflake ID, code is 10-11 characters
+	StrategySnowflake = "snowflake"
+	// StrategyRandom use random ID of ID_GEN_CODE_LENGTH characters
+	StrategyRandom = "random"
+	// StrategyHash use hash of long url, same long url always get the same code
+	StrategyHash = "hash"
+	// StrategyCounter use counter in database, code grows from 1 character
+	StrategyCounter = "counter"
+)
+
+// ErrNoFreeCode is returned when strategy can not find an unused code
+var ErrNoFreeCode = errors.New("idgenerator: no free code")
+
+// Strategy decide ID of new url and how ID is shown as short code.
+// Encode and Decode of every strategy are done by Codec of config.
+//
+// ID of every strategy is int64 stored in urls.id,
+// so cache, bloom filter and database work the same for all strategies.
+type Strategy interface {
+	// Name of strategy
+	Name() string
+
+	// NextID return ID for longURL, code of ID is allowed by CodeFilter.
+	// ID may still conflict with a concurrent insert, caller should retry on key conflict.
+	NextID(ctx context.Context, longURL string) (snowflake.SID, error)
+
+	// Encode ID into short code
+	Encode(id snowflake.SID) string
+
+	// Decode short code back to ID
+	Decode(code string) (snowflake.SID, error)
+}
+
+var _ model.CodeDecoder = (Strategy)(nil)
+
+// URLLookup find url by ID, it is implemented by database.URLShortenerDB
+type URLLookup interface {
+	GetFirstByID(ctx context.Context, sid snowflake.SID) (model.URL, error)
+}
+
+// Counter return increasing number, it is implemented by database.URLShortenerDB
+type Counter interface {
+	NextCounter(ctx context.Context) (int64, error)
+}
+
+// Store is what strategies need from database
+type Store interface {
+	URLLookup
+	Counter
+	HighWaterMarkStore
+}
+
+// NewStrategy create strategy of ID_GEN_STRATEGY.
+// lease is passed to snowflake Generator, see NewGenerator.
+func NewStrategy(cfg *urlshortenerconfig.Config, lease *nodelease.Lease, store Store) (Strategy, error) {
+	idCfg := cfg.IDGenerator
+
+	codec, err := NewCodec(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// ID_GEN_ALPHABET is already validated by NewCodec
+	alphabet, _ := model.ParseAlphabet(idCfg.Alphabet)
+
+	filter, err := NewCodeFilter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(idCfg.Strategy)) {
+	case StrategySnowflake, "":
+		var marks HighWaterMarkStore
+		if idCfg.HighWaterMarkEnabled {
+			marks = store
+		}
+
+		generator, err := NewGenerator(cfg, lease, marks)
+		if err != nil {
+			return nil, err
+		}
+		return &snowflakeStrategy{Codec: codec, filter: filter, generator: generator}, nil
+	case StrategyRandom:
+		if err := validateCodeLength(idCfg.CodeLength, alphabet); err != nil {
+			return nil, err
+		}
+		return &randomStrategy{
+			Codec:       codec,
+			alphabet:    alphabet,
+			filter:      filter,
+			lookup:      store,
+			length:      idCfg.CodeLength,
+			maxAttempts: max(idCfg.MaxAttempts, 1),
+		}, nil
+	case StrategyHash:
+		if err := validateCodeLength(idCfg.CodeLength, alphabet); err != nil {
+			return nil, err
+		}
+		return &hashStrategy{
+			Codec:    codec,
+			alphabet: alphabet,
+			filter:   filter,
+			lookup:   store,
+			length:   idCfg.CodeLength,
+		}, nil
+	case StrategyCounter:
+		return &counterStrategy{Codec: codec, filter: filter, counter: store}, nil
+	default:
+		return nil, fmt.Errorf("unknown ID_GEN_STRATEGY %q", idCfg.Strategy)
+	}
+}
+
+func validateCodeLength(length int, alphabet model.Alphabet) error {
+	if maxLength := alphabet.MaxCodeLength(); length < 1 || length > maxLength {
+		return fmt.Errorf("ID_GEN_CODE_LENGTH of %s must be in [1, %d], got %d", alphabet.Name(), maxLength, length)
+	}
+	return nil
+}
+
+// codeRange return [min, max) of ID whose code of alphabet has length characters
+func codeRange(length int, alphabet model.Alphabet) (int64, int64) {
+	base := alphabet.Base()
+
+	// lower starts from 1 for length 1, because ID 0 is not valid
+	lower, upper := int64(1), base
+	for range length - 1 {
+		lower *= base
+		upper *= base
+	}
+
+	return lower, upper
+}
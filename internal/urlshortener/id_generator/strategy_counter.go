@@ -0,0 +1,34 @@
+package idgenerator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TinyMurky/snowflake"
+)
+
+// counterStrategy use increasing counter in database as ID,
+// it gives the shortest codes but they are easy to enumerate.
+type counterStrategy struct {
+	Codec
+	filter  *CodeFilter
+	counter Counter
+}
+
+func (s *counterStrategy) Name() string {
+	return StrategyCounter
+}
+
+// NextID skip counter values of blocked code, they are never used
+func (s *counterStrategy) NextID(ctx context.Context, _ string) (snowflake.SID, error) {
+	return nextAllowedID(ctx, s.filter, s.Codec, s.nextCounter)
+}
+
+func (s *counterStrategy) nextCounter(ctx context.Context) (snowflake.SID, error) {
+	n, err := s.counter.NextCounter(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("NextCounter: %w", err)
+	}
+
+	return snowflake.SID(n), nil
+}
@@ -0,0 +1,53 @@
+package idgenerator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+// hashStrategy derive ID from sha256 of long url.
+// If ID is used by another long url, code is extended by one character.
+type hashStrategy struct {
+	Codec
+	alphabet model.Alphabet
+	filter   *CodeFilter
+	lookup   URLLookup
+	length   int
+}
+
+func (s *hashStrategy) Name() string {
+	return StrategyHash
+}
+
+func (s *hashStrategy) NextID(ctx context.Context, longURL string) (snowflake.SID, error) {
+	sum := sha256.Sum256([]byte(longURL))
+	hash := binary.BigEndian.Uint64(sum[:8])
+
+	for length := s.length; length <= s.alphabet.MaxCodeLength(); length++ {
+		lower, upper := codeRange(length, s.alphabet)
+		id := snowflake.SID(lower + int64(hash%uint64(upper-lower)))
+
+		// blocked code is extended like a collision
+		if !s.filter.Allowed(s.Encode(id)) {
+			blockedCodeSkipped.Add(1)
+			continue
+		}
+
+		u, err := s.lookup.GetFirstByID(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("GetFirstByID: %w", err)
+		}
+
+		if u.IsZero() || u.LongURL == longURL {
+			return id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w for hash of %q", ErrNoFreeCode, longURL)
+}
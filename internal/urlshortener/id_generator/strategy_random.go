@@ -0,0 +1,50 @@
+package idgenerator
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/TinyMurky/snowflake"
+
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+// randomStrategy pick random ID of fixed code length,
+// ID already in database is skipped.
+type randomStrategy struct {
+	Codec
+	alphabet    model.Alphabet
+	filter      *CodeFilter
+	lookup      URLLookup
+	length      int
+	maxAttempts int
+}
+
+func (s *randomStrategy) Name() string {
+	return StrategyRandom
+}
+
+func (s *randomStrategy) NextID(ctx context.Context, _ string) (snowflake.SID, error) {
+	lower, upper := codeRange(s.length, s.alphabet)
+
+	for range s.maxAttempts {
+		id := snowflake.SID(lower + rand.Int64N(upper-lower))
+
+		if !s.filter.Allowed(s.Encode(id)) {
+			blockedCodeSkipped.Add(1)
+			continue
+		}
+
+		u, err := s.lookup.GetFirstByID(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("GetFirstByID: %w", err)
+		}
+
+		if u.IsZero() {
+			return id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w after %d attempts of length %d", ErrNoFreeCode, s.maxAttempts, s.length)
+}
@@ -0,0 +1,23 @@
+package idgenerator
+
+import (
+	"context"
+
+	"github.com/TinyMurky/snowflake"
+)
+
+// snowflakeStrategy use snowflake ID from Generator
+type snowflakeStrategy struct {
+	Codec
+	filter    *CodeFilter
+	generator *Generator
+}
+
+func (s *snowflakeStrategy) Name() string {
+	return StrategySnowflake
+}
+
+// NextID ignore longURL, snowflake ID is unique by itself
+func (s *snowflakeStrategy) NextID(ctx context.Context, _ string) (snowflake.SID, error) {
+	return nextAllowedID(ctx, s.filter, s.Codec, s.generator.NextID)
+}
@@ -0,0 +1,130 @@
+package idgenerator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TinyMurky/snowflake"
+
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+)
+
+// fakeStore keep urls by ID in memory
+type fakeStore struct {
+	urls    map[snowflake.SID]string
+	counter int64
+	marks   map[int64]int64
+}
+
+func (s *fakeStore) GetFirstByID(_ context.Context, sid snowflake.SID) (model.URL, error) {
+	longURL, ok := s.urls[sid]
+	if !ok {
+		return model.URL{}, nil
+	}
+	return model.URL{ID: sid, LongURL: longURL}, nil
+}
+
+func (s *fakeStore) NextCounter(context.Context) (int64, error) {
+	s.counter++
+	return s.counter, nil
+}
+
+func (s *fakeStore) GetHighWaterMark(_ context.Context, nodeID int64) (int64, error) {
+	return s.marks[nodeID], nil
+}
+
+func (s *fakeStore) SaveHighWaterMark(_ context.Context, nodeID int64, unixMilli int64) error {
+	if s.marks == nil {
+		s.marks = map[int64]int64{}
+	}
+	s.marks[nodeID] = max(s.marks[nodeID], unixMilli)
+	return nil
+}
+
+func newTestStrategy(t *testing.T, name string, length int, store Store) Strategy {
+	t.Helper()
+
+	cfg := &urlshortenerconfig.Config{}
+	cfg.IDGenerator.EpochTimeStartFrom = "2025-12-14"
+	cfg.IDGenerator.Strategy = name
+	cfg.IDGenerator.CodeLength = length
+	cfg.IDGenerator.MaxAttempts = 5
+
+	strategy, err := NewStrategy(cfg, nil, store)
+	if err != nil {
+		t.Fatalf("NewStrategy(%s): %v", name, err)
+	}
+	return strategy
+}
+
+func TestStrategyRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	for _, name := range []string{StrategySnowflake, StrategyRandom, StrategyHash, StrategyCounter} {
+		store := &fakeStore{urls: map[snowflake.SID]string{}}
+		strategy := newTestStrategy(t, name, 6, store)
+
+		id, err := strategy.NextID(ctx, "https://example.com")
+		if err != nil {
+			t.Fatalf("%s NextID: %v", name, err)
+		}
+
+		code := strategy.Encode(id)
+		if name == StrategyRandom || name == StrategyHash {
+			if len(code) != 6 {
+				t.Errorf("%s: expect 6 characters code, got %q", name, code)
+			}
+		}
+
+		decoded, err := strategy.Decode(code)
+		if err != nil || decoded != id {
+			t.Errorf("%s: Decode(%q) = %d, %v, want %d", name, code, decoded, err, id)
+		}
+	}
+}
+
+func TestHashStrategyExtend(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{urls: map[snowflake.SID]string{}}
+	strategy := newTestStrategy(t, StrategyHash, 3, store)
+
+	id, err := strategy.NextID(ctx, "https://example.com/a")
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	// same long url get the same ID
+	store.urls[id] = "https://example.com/a"
+	if again, _ := strategy.NextID(ctx, "https://example.com/a"); again != id {
+		t.Errorf("expect same ID %d, got %d", id, again)
+	}
+
+	// ID taken by another url is extended
+	store.urls[id] = "https://example.com/other"
+	extended, err := strategy.NextID(ctx, "https://example.com/a")
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	if code := strategy.Encode(extended); len(code) != 4 {
+		t.Errorf("expect 4 characters code, got %q", code)
+	}
+}
+
+func TestRandomStrategyNoFreeCode(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{urls: map[snowflake.SID]string{}}
+
+	// every 1 character code is taken
+	for id := snowflake.SID(1); id < 62; id++ {
+		store.urls[id] = "https://example.com"
+	}
+
+	strategy := newTestStrategy(t, StrategyRandom, 1, store)
+
+	if _, err := strategy.NextID(ctx, "https://example.com/new"); !errors.Is(err, ErrNoFreeCode) {
+		t.Errorf("expect ErrNoFreeCode, got %v", err)
+	}
+}
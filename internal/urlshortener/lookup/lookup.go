@@ -0,0 +1,142 @@
+// Package lookup find url of ID through bloom filter, cache and database,
+// it is the read path shared by handlers that need the long url of a code.
+package lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/cache"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/singleflight"
+	pkgcache "github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// ErrNotFound is returned by Find if url of ID does not exist
+var ErrNotFound = errors.New("lookup: url not found")
+
+// Lookup find url of ID.
+//
+// Bloom filter is checked first, then cache, then database through singleflight,
+// so concurrent misses of the same ID cost one query. Url found in database
+// is written back to cache.
+// Bloom filter and cache are behind the redis circuit breaker,
+// they are skipped when redis is down.
+type Lookup struct {
+	cache        *cache.URLShortenerCache
+	bloomFilter  *bloomfilter.URLShortenerBloomFilter
+	db           *database.URLShortenerDB
+	singleFlight *singleflight.Group
+	redisBreaker *circuitbreaker.Breaker
+	cacheTTL     time.Duration
+}
+
+// New create Lookup on dependencies of env
+func New(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (*Lookup, error) {
+	bloomFilter, err := bloomfilter.New(ctx, env.BloomFilter(), cfg.BloomFilterConfig())
+	if err != nil {
+		return nil, fmt.Errorf("bloomfilter.New: %w", err)
+	}
+
+	return &Lookup{
+		cache:        cache.New(env.Cache()),
+		bloomFilter:  bloomFilter,
+		db:           database.New(env.Database()),
+		singleFlight: singleflight.New(env.SingleFlight()),
+		redisBreaker: env.RedisCircuitBreaker(),
+		cacheTTL:     time.Millisecond * time.Duration(cfg.RedisCacheTTLInMiliSec),
+	}, nil
+}
+
+// Find return url of u.ID, ErrNotFound is returned if it does not exist.
+func (l *Lookup) Find(ctx context.Context, u model.URL) (model.URL, error) {
+	logger := logging.FromContext(ctx).Named("lookup")
+
+	// check bloom filter first
+	isURLExists, err := l.isURLBase62IDExist(ctx, u)
+	if err != nil {
+		// bloom filter is unavailable, treat it as "might exist"
+		isURLExists = true
+		logger.Warnf("bloom filter skipped: %s", err.Error())
+	}
+
+	if !isURLExists {
+		return u, ErrNotFound
+	}
+
+	cachedURL, err := l.getURLFromCache(ctx, u)
+	if err != nil {
+		logger.Warnf("cache skipped: %s", err.Error())
+	}
+
+	if err == nil && !cachedURL.IsEmptyLongURL() {
+		// 找到 cache 的資料
+		return cachedURL, nil
+	}
+
+	v, err, _ := l.singleFlight.Do(u.GetIDBase62(), func() (any, error) {
+		u, err := l.db.GetFirstByID(ctx, u.ID)
+		if err != nil {
+			return model.URL{}, err
+		}
+
+		if u.IsEmptyLongURL() {
+			return u, nil
+		}
+
+		err = l.redisBreaker.Do(func() error {
+			return l.cache.SetURL(ctx, u, l.cacheTTL)
+		})
+		if err != nil {
+			// database worked, so cache failure should not fail the request
+			logger.Warnf("cache SetURL skipped: %s", err.Error())
+		}
+
+		return u, nil
+	})
+
+	if err != nil {
+		return u, fmt.Errorf("singleFlight GetFirstByID: %w", err)
+	}
+
+	found := v.(model.URL)
+	if found.IsEmptyLongURL() {
+		return u, ErrNotFound
+	}
+
+	return found, nil
+}
+
+// isURLBase62IDExist check bloom filter through circuit breaker
+func (l *Lookup) isURLBase62IDExist(ctx context.Context, u model.URL) (bool, error) {
+	var isExist bool
+	err := l.redisBreaker.Do(func() error {
+		var err error
+		isExist, err = l.bloomFilter.IsURLBase62IDExist(ctx, u)
+		return err
+	})
+	return isExist, err
+}
+
+// getURLFromCache get url from cache through circuit breaker,
+// cache miss is returned as url with empty LongURL and nil error.
+func (l *Lookup) getURLFromCache(ctx context.Context, u model.URL) (model.URL, error) {
+	cachedURL := u
+	err := l.redisBreaker.Do(func() error {
+		var err error
+		cachedURL, err = l.cache.GetURL(ctx, u)
+		if errors.Is(err, pkgcache.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+	return cachedURL, err
+}
@@ -0,0 +1,86 @@
+package lookup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/bloomfilter"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/model"
+	pkgbloomfilter "github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+	"github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+	"github.com/TinyMurky/tinyurl/pkg/singleflight"
+)
+
+func TestFindAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewTestDatabase(t)
+
+	cfg := &urlshortenerconfig.Config{
+		BloomFilter: pkgbloomfilter.Config{
+			Backend:                   pkgbloomfilter.BackendMemory,
+			RedisBloomFilterErrorRate: 0.001,
+			RedisBloomFilterCapacity:  10,
+		},
+		RedisCacheTTLInMiliSec: 1000,
+	}
+
+	u := model.URL{ID: 12345, LongURL: "https://example.com/a", CreatedAt: time.Now().UTC()}
+	if err := database.New(db).CreateURL(ctx, u); err != nil {
+		t.Fatalf("CreateURL: %v", err)
+	}
+
+	for _, filterType := range []pkgbloomfilter.Type{pkgbloomfilter.TypeBloom, pkgbloomfilter.TypeCuckoo} {
+		cfg.BloomFilter.Type = filterType
+
+		// new process has empty filter and cache
+		filter, err := pkgbloomfilter.NewFromEnv(ctx, &cfg.BloomFilter)
+		if err != nil {
+			t.Fatalf("NewFromEnv: %v", err)
+		}
+
+		env := serverenv.New(ctx,
+			serverenv.WithDatabase(db),
+			serverenv.WithCache(cache.NewMemoryCache(10)),
+			serverenv.WithBloomFilter(filter),
+			serverenv.WithSingleFlight(singleflight.New(ctx, nil)),
+			serverenv.WithRedisCircuitBreaker(circuitbreaker.New(ctx, "redis", nil)),
+		)
+
+		bf, err := bloomfilter.New(ctx, filter, &cfg.BloomFilter)
+		if err != nil {
+			t.Fatalf("bloomfilter.New: %v", err)
+		}
+
+		rebuilt, err := bf.RebuildIfEmpty(ctx, database.New(db), 10)
+		if err != nil {
+			t.Fatalf("%s: RebuildIfEmpty: %v", filterType, err)
+		}
+		if !rebuilt {
+			t.Errorf("%s: empty filter should be rebuilt", filterType)
+		}
+
+		l, err := New(ctx, cfg, env)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+
+		found, err := l.Find(ctx, model.URL{ID: u.ID})
+		if err != nil {
+			t.Fatalf("%s: Find: %v", filterType, err)
+		}
+
+		if found.LongURL != u.LongURL {
+			t.Errorf("%s: LongURL = %q, want %q", filterType, found.LongURL, u.LongURL)
+		}
+
+		// filter is not empty any more
+		if rebuilt, _ := bf.RebuildIfEmpty(ctx, database.New(db), 10); rebuilt {
+			t.Errorf("%s: filter with items should not be rebuilt", filterType)
+		}
+	}
+}
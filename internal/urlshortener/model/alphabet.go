@@ -0,0 +1,134 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+
+	"github.com/TinyMurky/snowflake"
+)
+
+// Alphabet is the characters that ID is encoded with in short code,
+// cache key and bloom filter item.
+type Alphabet struct {
+	name  string
+	chars string
+
+	// caseInsensitive alphabet decode lower case and
+	// look-alike characters (ex: "O" as "0")
+	caseInsensitive bool
+
+	encode func(snowflake.SID) string
+	decode func(string) (snowflake.SID, error)
+}
+
+var (
+	// AlphabetBase62 is 0-9, A-Z, a-z. It is the default and gives the shortest code.
+	AlphabetBase62 = Alphabet{
+		name:   "base62",
+		chars:  "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+		encode: snowflake.SID.Base62,
+		decode: snowflake.ParseBase62,
+	}
+
+	// AlphabetBase58 is the Bitcoin alphabet, base62 without "0", "O", "I" and "l"
+	AlphabetBase58 = Alphabet{
+		name:   "base58",
+		chars:  "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz",
+		encode: snowflake.SID.Base58,
+		decode: snowflake.ParseBase58,
+	}
+
+	// AlphabetBase32 is Crockford base32, it has no "I", "L", "O" and "U",
+	// and is decoded case-insensitively with "O" as "0", "I" and "L" as "1".
+	AlphabetBase32 = Alphabet{
+		name:            "base32",
+		chars:           "0123456789ABCDEFGHJKMNPQRSTVWXYZ",
+		caseInsensitive: true,
+		encode:          snowflake.SID.Base32,
+		decode:          snowflake.ParseBase32,
+	}
+)
+
+// crockfordReplacer map lower case and look-alike characters into Crockford base32
+var crockfordReplacer = strings.NewReplacer("O", "0", "I", "1", "L", "1")
+
+// codeAlphabet is the alphabet of ID_GEN_ALPHABET, see SetAlphabet
+var codeAlphabet atomic.Pointer[Alphabet]
+
+// ParseAlphabet return alphabet of name: base62 | base58 | base32
+func ParseAlphabet(name string) (Alphabet, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case AlphabetBase62.name, "":
+		return AlphabetBase62, nil
+	case AlphabetBase58.name:
+		return AlphabetBase58, nil
+	case AlphabetBase32.name:
+		return AlphabetBase32, nil
+	default:
+		return Alphabet{}, fmt.Errorf("unknown alphabet %q", name)
+	}
+}
+
+// SetAlphabet set the alphabet used by URL.GetIDBase62 and NewURLFromBase62.
+// It should be called once on start up before serving, base62 is used until then.
+func SetAlphabet(a Alphabet) {
+	codeAlphabet.Store(&a)
+}
+
+// CurrentAlphabet return the alphabet set by SetAlphabet
+func CurrentAlphabet() Alphabet {
+	if a := codeAlphabet.Load(); a != nil {
+		return *a
+	}
+	return AlphabetBase62
+}
+
+// Name of alphabet, ex: base62
+func (a Alphabet) Name() string {
+	return a.name
+}
+
+// Chars return characters of alphabet in order of value
+func (a Alphabet) Chars() string {
+	return a.chars
+}
+
+// Base is the number of characters
+func (a Alphabet) Base() int64 {
+	return int64(len(a.chars))
+}
+
+// Zero is the character of value 0, encoded positive ID never starts with it
+func (a Alphabet) Zero() byte {
+	return a.chars[0]
+}
+
+// MaxCodeLength is the longest code of which every value fits in int64
+func (a Alphabet) MaxCodeLength() int {
+	length := 0
+	for n := int64(math.MaxInt64); n >= a.Base(); n /= a.Base() {
+		length++
+	}
+	return length
+}
+
+// Encode ID into code
+func (a Alphabet) Encode(id snowflake.SID) string {
+	return a.encode(id)
+}
+
+// Decode code back to ID, code is normalized first
+func (a Alphabet) Decode(code string) (snowflake.SID, error) {
+	return a.decode(a.Normalize(code))
+}
+
+// Normalize turn code typed by people into characters of alphabet,
+// it only changes code of case-insensitive alphabet.
+func (a Alphabet) Normalize(code string) string {
+	if !a.caseInsensitive {
+		return code
+	}
+	return crockfordReplacer.Replace(strings.ToUpper(code))
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/TinyMurky/snowflake"
+)
+
+func TestAlphabet(t *testing.T) {
+	tests := []struct {
+		alphabet      Alphabet
+		maxCodeLength int
+	}{
+		{AlphabetBase62, 10},
+		{AlphabetBase58, 10},
+		{AlphabetBase32, 12},
+	}
+
+	for _, tt := range tests {
+		if got := tt.alphabet.MaxCodeLength(); got != tt.maxCodeLength {
+			t.Errorf("%s MaxCodeLength() = %d, want %d", tt.alphabet.Name(), got, tt.maxCodeLength)
+		}
+
+		for _, id := range []snowflake.SID{1, 12345, 1 << 62} {
+			code := tt.alphabet.Encode(id)
+			if code[0] == tt.alphabet.Zero() {
+				t.Errorf("%s code %q of %d starts with zero", tt.alphabet.Name(), code, id)
+			}
+
+			if decoded, err := tt.alphabet.Decode(code); err != nil || decoded != id {
+				t.Errorf("%s Decode(%q) = %d, %v, want %d", tt.alphabet.Name(), code, decoded, err, id)
+			}
+		}
+	}
+}
+
+func TestSetAlphabet(t *testing.T) {
+	t.Cleanup(func() { SetAlphabet(AlphabetBase62) })
+
+	SetAlphabet(AlphabetBase32)
+	u := URL{ID: 1024}
+
+	if got := u.GetIDBase62(); got != "100" {
+		t.Fatalf("GetIDBase62() = %q, want %q", got, "100")
+	}
+
+	parsed, err := NewURLFromBase62("loo")
+	if err != nil || parsed.ID != u.ID {
+		t.Errorf("NewURLFromBase62(%q) = %d, %v, want %d", "loo", parsed.ID, err, u.ID)
+	}
+}
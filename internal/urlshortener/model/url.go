@@ -3,6 +3,8 @@ package model
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/TinyMurky/snowflake"
@@ -15,9 +17,10 @@ type URL struct {
 	CreatedAt time.Time     `json:"created_at"`
 }
 
-// GetIDBase62 returns the snowflake id in base62 format.
+// GetIDBase62 returns the snowflake id encoded by alphabet of ID_GEN_ALPHABET,
+// which is base62 by default. See SetAlphabet.
 func (u *URL) GetIDBase62() string {
-	return u.ID.Base62()
+	return CurrentAlphabet().Encode(u.ID)
 }
 
 // IsEmptyLongURL check if longURL is empty
@@ -25,6 +28,15 @@ func (u *URL) IsEmptyLongURL() bool {
 	return u.LongURL == ""
 }
 
+// Host return lower case host of LongURL without port, empty if it has no host
+func (u *URL) Host() string {
+	parsed, err := url.Parse(u.LongURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
 // IsZero will return that if URL is zero value
 func (u *URL) IsZero() bool {
 	if u == nil {
@@ -46,11 +58,37 @@ func NewURL(id snowflake.SID, longURL string) *URL {
 	}
 }
 
-// NewURLFromBase62 can create URL from base62 for search query
+// ShortURL return url that redirect to long url of code,
+// prefix is SHORT_URL_PREFIX
+func ShortURL(prefix string, code string) (string, error) {
+	return url.JoinPath(prefix, "api", "v1", "shortUrl", code)
+}
+
+// CodeDecoder decode short code into ID,
+// it is implemented by strategies of idgenerator.
+type CodeDecoder interface {
+	Decode(code string) (snowflake.SID, error)
+}
+
+// NewURLFromCode create URL from short code for search query,
+// code is decoded by decoder of the configured strategy.
+func NewURLFromCode(code string, decoder CodeDecoder) (URL, error) {
+	sid, err := decoder.Decode(code)
+	if err != nil {
+		return URL{}, fmt.Errorf("invalid code: %w", err)
+	}
+
+	return URL{
+		ID: sid,
+	}, nil
+}
+
+// NewURLFromBase62 can create URL from ID encoded by GetIDBase62 for search query
 func NewURLFromBase62(base62 string) (URL, error) {
-	sid, err := snowflake.ParseBase62(base62)
+	alphabet := CurrentAlphabet()
+	sid, err := alphabet.Decode(base62)
 	if err != nil {
-		return URL{}, fmt.Errorf("invalid base62 string: %w", err)
+		return URL{}, fmt.Errorf("invalid %s string: %w", alphabet.Name(), err)
 	}
 
 	return URL{
@@ -9,7 +9,9 @@ package urlshortener
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/TinyMurky/tinyurl/internal/middleware"
 	"github.com/TinyMurky/tinyurl/internal/serverenv"
@@ -34,8 +36,10 @@ func NewServer(cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) *Server
 }
 
 // Routes initializes the routing logic and registers all application endpoints.
-// It returns the top-level http.Handler that can be used by the HTTP server.
-func (s *Server) Routes(ctx context.Context) http.Handler {
+// It returns the top-level http.Handler that can be used by the HTTP server,
+// or error if any handler can not be created.
+// Background jobs that must finish before database is closed are added to background.
+func (s *Server) Routes(ctx context.Context, background *sync.WaitGroup) (http.Handler, error) {
 	logger := logging.FromContext(ctx).Named("urlshortener")
 
 	router := http.NewServeMux()
@@ -45,7 +49,12 @@ func (s *Server) Routes(ctx context.Context) http.Handler {
 	// Mount the API handler under the "/api/" path.
 	// We use StripPrefix so the inner handler doesn't need to know about the "/api" prefix.
 	// Note: The trailing slash in "/api/" ensures it matches all paths under /api.
-	router.Handle("/api/", http.StripPrefix("/api", apiHandler.Handler()))
+	apiRouter, err := apiHandler.Handler(ctx, background)
+	if err != nil {
+		return nil, fmt.Errorf("api: %w", err)
+	}
+
+	router.Handle("/api/", http.StripPrefix("/api", apiRouter))
 
 	// Wrap router with middlewares
 	// request will perform middleware before it enter the route
@@ -53,5 +62,5 @@ func (s *Server) Routes(ctx context.Context) http.Handler {
 		middleware.PopulateLogger(logger),
 	)
 
-	return middlewareStack(router)
+	return middlewareStack(router), nil
 }
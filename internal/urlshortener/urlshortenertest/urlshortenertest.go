@@ -0,0 +1,197 @@
+// Package urlshortenertest provide config and server env of urlshortener for tests.
+// Every dependency lives in memory, database is sqlite with migrations applied.
+package urlshortenertest
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-envconfig"
+
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/blocklist"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/pkg/bloomfilter"
+	"github.com/TinyMurky/tinyurl/pkg/cache"
+	"github.com/TinyMurky/tinyurl/pkg/circuitbreaker"
+	"github.com/TinyMurky/tinyurl/pkg/singleflight"
+)
+
+// NewConfig return config of default values, overridden by env
+func NewConfig(tb testing.TB, env map[string]string) *urlshortenerconfig.Config {
+	tb.Helper()
+
+	values := map[string]string{
+		"CACHE_BACKEND":        "memory",
+		"BLOOM_FILTER_BACKEND": "memory",
+	}
+	maps.Copy(values, env)
+
+	var cfg urlshortenerconfig.Config
+	if err := envconfig.ProcessWith(context.Background(), &envconfig.Config{
+		Target:   &cfg,
+		Lookuper: envconfig.MapLookuper(values),
+	}); err != nil {
+		tb.Fatalf("process config: %v", err)
+	}
+
+	return &cfg
+}
+
+// NewServerEnv return server env of cfg on memory cache, memory filter and sqlite.
+// opts are applied last, ex: serverenv.WithCache to replace cache with a spy.
+func NewServerEnv(tb testing.TB, cfg *urlshortenerconfig.Config, opts ...serverenv.Option) *serverenv.ServerEnv {
+	tb.Helper()
+	ctx := context.Background()
+
+	filter, err := bloomfilter.NewFromEnv(ctx, cfg.BloomFilterConfig())
+	if err != nil {
+		tb.Fatalf("bloomfilter.NewFromEnv: %v", err)
+	}
+
+	defaults := []serverenv.Option{
+		serverenv.WithDatabase(database.NewTestDatabase(tb)),
+		serverenv.WithCache(cache.NewMemoryCache(cfg.Cache.MemoryMaxEntries)),
+		serverenv.WithBloomFilter(filter),
+		serverenv.WithSingleFlight(singleflight.New(ctx, cfg.SingleFlightConfig())),
+		serverenv.WithRedisCircuitBreaker(circuitbreaker.New(ctx, "redis", cfg.CircuitBreakerConfig())),
+	}
+
+	return serverenv.New(ctx, append(defaults, opts...)...)
+}
+
+// NewBlocklist return blocklist of cfg on cache of env, rules are loaded once and not reloaded
+func NewBlocklist(tb testing.TB, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) *blocklist.Blocklist {
+	tb.Helper()
+
+	b, err := blocklist.New(context.Background(), env.Cache(), env.RedisCircuitBreaker(), cfg)
+	if err != nil {
+		tb.Fatalf("blocklist.New: %v", err)
+	}
+
+	return b
+}
+
+// Calls count calls by method name, it is safe for concurrent use
+type Calls struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *Calls) record(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[method]++
+}
+
+// Count return number of calls of method
+func (c *Calls) Count(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[method]
+}
+
+// Total return number of calls of all methods
+func (c *Calls) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := 0
+	for _, n := range c.counts {
+		total += n
+	}
+	return total
+}
+
+// Reset forget all calls
+func (c *Calls) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.counts)
+}
+
+// SpyFilter is bloomfilter.Filter that count calls and pass them to Filter
+type SpyFilter struct {
+	bloomfilter.Filter
+	Calls
+}
+
+// Reserve filter
+func (f *SpyFilter) Reserve(ctx context.Context, key string, errorRate float64, capacity int64) error {
+	f.record("Reserve")
+	return f.Filter.Reserve(ctx, key, errorRate, capacity)
+}
+
+// Add item
+func (f *SpyFilter) Add(ctx context.Context, key string, item string) error {
+	f.record("Add")
+	return f.Filter.Add(ctx, key, item)
+}
+
+// AddMany add items
+func (f *SpyFilter) AddMany(ctx context.Context, key string, items []string) error {
+	f.record("AddMany")
+	return f.Filter.AddMany(ctx, key, items)
+}
+
+// Exists check item
+func (f *SpyFilter) Exists(ctx context.Context, key string, item string) (bool, error) {
+	f.record("Exists")
+	return f.Filter.Exists(ctx, key, item)
+}
+
+// ExistsMany check items
+func (f *SpyFilter) ExistsMany(ctx context.Context, key string, items []string) ([]bool, error) {
+	f.record("ExistsMany")
+	return f.Filter.ExistsMany(ctx, key, items)
+}
+
+// Info of filter
+func (f *SpyFilter) Info(ctx context.Context, key string) (bloomfilter.Info, error) {
+	f.record("Info")
+	return f.Filter.Info(ctx, key)
+}
+
+// SpyCache is cache.Cache that count calls and pass them to Cache
+type SpyCache struct {
+	cache.Cache
+	Calls
+}
+
+// Get value of key
+func (c *SpyCache) Get(ctx context.Context, key string) (string, error) {
+	c.record("Get")
+	return c.Cache.Get(ctx, key)
+}
+
+// Set value of key
+func (c *SpyCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	c.record("Set")
+	return c.Cache.Set(ctx, key, value, expiration)
+}
+
+// SetMany set values
+func (c *SpyCache) SetMany(ctx context.Context, values map[string]string, expiration time.Duration) error {
+	c.record("SetMany")
+	return c.Cache.SetMany(ctx, values, expiration)
+}
+
+// Del remove keys
+func (c *SpyCache) Del(ctx context.Context, keys ...string) error {
+	c.record("Del")
+	return c.Cache.Del(ctx, keys...)
+}
+
+// TTL of key
+func (c *SpyCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.record("TTL")
+	return c.Cache.TTL(ctx, key)
+}
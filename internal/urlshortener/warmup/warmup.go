@@ -0,0 +1,82 @@
+// Package warmup load links from database into cache before server start,
+// so the first requests after a deploy or a cache flush don't all hit database.
+package warmup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/internal/serverenv"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/cache"
+	urlshortenerconfig "github.com/TinyMurky/tinyurl/internal/urlshortener/config"
+	"github.com/TinyMurky/tinyurl/internal/urlshortener/database"
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// Run load the newest links into cache within the time budget of config.
+// It returns number of links loaded. Running out of time is not an error,
+// the rest of links will be cached on read.
+//
+// Links are ranked by recency of creation, not by click count.
+func Run(ctx context.Context, cfg *urlshortenerconfig.Config, env *serverenv.ServerEnv) (int, error) {
+	logger := logging.FromContext(ctx).Named("cache_warmup")
+	warmUpCfg := cfg.CacheWarmUp
+
+	if !warmUpCfg.Enabled || warmUpCfg.Limit <= 0 {
+		logger.Info("cache warm up disabled")
+		return 0, nil
+	}
+
+	batchSize := warmUpCfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = warmUpCfg.Limit
+	}
+
+	// timeout not positive means no time budget
+	cancel := context.CancelFunc(func() {})
+	if warmUpCfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, warmUpCfg.Timeout)
+	}
+	defer cancel()
+
+	start := time.Now()
+	cacheTTL := time.Millisecond * time.Duration(cfg.RedisCacheTTLInMiliSec)
+	db := database.New(env.Database())
+	urlCache := cache.New(env.Cache())
+
+	urls, err := db.ListLatest(ctx, warmUpCfg.Limit)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			logger.Warnf("cache warm up timeout (%s) while reading database", warmUpCfg.Timeout)
+			return 0, nil
+		}
+		return 0, fmt.Errorf("database ListLatest: %w", err)
+	}
+
+	loaded := 0
+
+	for i := 0; i < len(urls); i += batchSize {
+		if ctx.Err() != nil {
+			logger.Warnf("cache warm up timeout (%s), %d of %d links loaded", warmUpCfg.Timeout, loaded, len(urls))
+			return loaded, nil
+		}
+
+		end := min(i+batchSize, len(urls))
+
+		if err := urlCache.SetURLs(ctx, urls[i:end], cacheTTL); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				logger.Warnf("cache warm up timeout (%s), %d of %d links loaded", warmUpCfg.Timeout, loaded, len(urls))
+				return loaded, nil
+			}
+			return loaded, fmt.Errorf("cache SetURLs: %w", err)
+		}
+
+		loaded = end
+	}
+
+	logger.Infof("cache warm up loaded %d links in %s", loaded, time.Since(start))
+
+	return loaded, nil
+}
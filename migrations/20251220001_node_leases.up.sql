@@ -0,0 +1,11 @@
+-- BEGIN;
+    -- 每個 snowflake node ID 同時只能被一個 process 租用
+    CREATE TABLE IF NOT EXISTS node_leases (
+        node_id INTEGER PRIMARY KEY,
+
+        owner TEXT NOT NULL,
+
+        -- unix milliseconds
+        expires_at INTEGER NOT NULL
+    );
+-- COMMIT;
@@ -0,0 +1,8 @@
+-- BEGIN;
+    -- ID_GEN_STRATEGY=counter 使用的遞增計數器
+    CREATE TABLE IF NOT EXISTS code_counter (
+        name TEXT PRIMARY KEY,
+
+        value INTEGER NOT NULL
+    );
+-- COMMIT;
@@ -0,0 +1,3 @@
+-- BEGIN;
+    DROP TABLE IF EXISTS id_high_water_marks;
+-- COMMIT;
@@ -0,0 +1,9 @@
+-- BEGIN;
+    -- 每個 node 可能已發出的最大 timestamp，用來偵測時鐘倒退
+    CREATE TABLE IF NOT EXISTS id_high_water_marks (
+        node_id INTEGER PRIMARY KEY,
+
+        -- unix milliseconds
+        timestamp_ms INTEGER NOT NULL
+    );
+-- COMMIT;
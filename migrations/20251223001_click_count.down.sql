@@ -0,0 +1,3 @@
+-- BEGIN;
+    ALTER TABLE urls DROP COLUMN click_count;
+-- COMMIT;
@@ -0,0 +1,4 @@
+-- BEGIN;
+    -- redirect 次數，由 server 批次累加，不是即時的
+    ALTER TABLE urls ADD COLUMN click_count INTEGER NOT NULL DEFAULT 0;
+-- COMMIT;
@@ -0,0 +1,5 @@
+-- BEGIN;
+    DROP INDEX IF EXISTS urls_created_at_id_index;
+    DROP INDEX IF EXISTS urls_host_id_index;
+    ALTER TABLE urls DROP COLUMN host;
+-- COMMIT;
@@ -0,0 +1,18 @@
+-- BEGIN;
+    -- long_url 的 host (小寫, 不含 port)，給 GET /api/v1/links 的 host 篩選用
+    ALTER TABLE urls ADD COLUMN host TEXT NOT NULL DEFAULT '';
+
+    -- 既有資料由 long_url 推算 host: scheme://[userinfo@]host[:port][/path][?query][#fragment]
+    UPDATE urls SET host = lower(substr(long_url, instr(long_url, '://') + 3)) WHERE instr(long_url, '://') > 0;
+    UPDATE urls SET host = substr(host, 1, instr(host, '/') - 1) WHERE instr(host, '/') > 0;
+    UPDATE urls SET host = substr(host, 1, instr(host, '?') - 1) WHERE instr(host, '?') > 0;
+    UPDATE urls SET host = substr(host, 1, instr(host, '#') - 1) WHERE instr(host, '#') > 0;
+    UPDATE urls SET host = substr(host, instr(host, '@') + 1) WHERE instr(host, '@') > 0;
+    -- port, ipv6 host is in [] and has no port after stripping them
+    UPDATE urls SET host = substr(host, 1, instr(host, ':') - 1) WHERE instr(host, ':') > 0 AND host NOT LIKE '[%';
+    UPDATE urls SET host = substr(host, 2, instr(host, ']') - 2) WHERE host LIKE '[%]%';
+
+    -- keyset pagination 依 id 由新到舊，篩選條件加上 id 才能接著分頁
+    CREATE INDEX IF NOT EXISTS urls_host_id_index ON urls (host, id);
+    CREATE INDEX IF NOT EXISTS urls_created_at_id_index ON urls (created_at, id);
+-- COMMIT;
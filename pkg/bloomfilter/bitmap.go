@@ -0,0 +1,214 @@
+package bloomfilter
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// bitmapHeaderBits is the size of header stored in front of the bitmap.
+// Header is 4 signed 64 bit big endian integer (BITFIELD i64):
+//
+//	#0 m        number of bits
+//	#1 k        number of hash functions
+//	#2 capacity
+//	#3 items    number of add, duplicated item is counted
+//
+// Keeping header and bits in the same key makes Rename atomic
+// and keeps the filter in one hash slot in cluster mode.
+const bitmapHeaderBits = 4 * 64
+
+// errBitmapNotReserved is the error reply of lua script
+// when filter of key is not reserved yet.
+const errBitmapNotReserved = "NOFILTER"
+
+// bitmapAddScript set k bits of every item, ARGV is pairs of (h1, h2).
+// Locations are computed the same as bloomLocations, h1 and h2 are 32 bit
+// so that (h1 + i * h2) stays exact in double precision number of lua.
+var bitmapAddScript = redis.NewScript(`
+local hdr = redis.call('BITFIELD', KEYS[1], 'GET', 'i64', 0, 'GET', 'i64', 64)
+local m, k = hdr[1], hdr[2]
+if m == 0 then
+	return redis.error_reply('` + errBitmapNotReserved + `')
+end
+local n = 0
+for j = 1, #ARGV, 2 do
+	local h1, h2 = tonumber(ARGV[j]), tonumber(ARGV[j + 1])
+	for i = 0, k - 1 do
+		redis.call('SETBIT', KEYS[1], ` + fmt.Sprint(bitmapHeaderBits) + ` + (h1 + i * h2) % m, 1)
+	end
+	n = n + 1
+end
+redis.call('BITFIELD', KEYS[1], 'INCRBY', 'i64', 192, n)
+return n
+`)
+
+// bitmapExistsScript return 1 for every item whose k bits are all set and 0 for others,
+// ARGV is pairs of (h1, h2) like bitmapAddScript.
+var bitmapExistsScript = redis.NewScript(`
+local hdr = redis.call('BITFIELD', KEYS[1], 'GET', 'i64', 0, 'GET', 'i64', 64)
+local m, k = hdr[1], hdr[2]
+local result = {}
+for j = 1, #ARGV, 2 do
+	local exist = 0
+	if m ~= 0 then
+		exist = 1
+		local h1, h2 = tonumber(ARGV[j]), tonumber(ARGV[j + 1])
+		for i = 0, k - 1 do
+			if redis.call('GETBIT', KEYS[1], ` + fmt.Sprint(bitmapHeaderBits) + ` + (h1 + i * h2) % m) == 0 then
+				exist = 0
+				break
+			end
+		end
+	end
+	result[#result + 1] = exist
+end
+return result
+`)
+
+// BitmapFilter is bloom filter on plain redis bitmap (SETBIT / GETBIT),
+// it works without RedisBloom module (ex: managed redis).
+// Like MemoryFilter, it does not scale up when capacity is exceeded.
+// RDB can be standalone, sentinel or cluster client.
+type BitmapFilter struct {
+	RDB redis.UniversalClient
+
+	defaultErrorRate float64
+	defaultCapacity  int64
+}
+
+var _ Filter = (*BitmapFilter)(nil)
+
+// NewBitmapFilter create filter on redis client, filter of key that Add before
+// Reserve is created with defaultErrorRate and defaultCapacity.
+func NewBitmapFilter(rdb redis.UniversalClient, defaultErrorRate float64, defaultCapacity int64) *BitmapFilter {
+	return &BitmapFilter{
+		RDB:              rdb,
+		defaultErrorRate: defaultErrorRate,
+		defaultCapacity:  defaultCapacity,
+	}
+}
+
+// Reserve filter by writing header with SET NX,
+// bits are allocated by redis on first SETBIT.
+func (f *BitmapFilter) Reserve(ctx context.Context, key string, errorRate float64, capacity int64) error {
+	m, k := bloomSize(errorRate, capacity)
+
+	// redis string is limited to 512MB
+	if m+bitmapHeaderBits > 512*1024*1024*8 {
+		return fmt.Errorf("bitmap of %d bits is too large for redis string", m)
+	}
+
+	header := make([]byte, bitmapHeaderBits/8)
+	binary.BigEndian.PutUint64(header[0:], m)
+	binary.BigEndian.PutUint64(header[8:], k)
+	binary.BigEndian.PutUint64(header[16:], uint64(max(capacity, 1)))
+
+	return f.RDB.SetNX(ctx, key, header, 0).Err()
+}
+
+// Add item
+func (f *BitmapFilter) Add(ctx context.Context, key string, item string) error {
+	return f.AddMany(ctx, key, []string{item})
+}
+
+// AddMany add items in one lua script call
+func (f *BitmapFilter) AddMany(ctx context.Context, key string, items []string) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, 2*len(items))
+	for _, item := range items {
+		h1, h2 := bloomHash(item)
+		args = append(args, h1, h2)
+	}
+
+	err := bitmapAddScript.Run(ctx, f.RDB, []string{key}, args...).Err()
+	if err == nil || !strings.Contains(err.Error(), errBitmapNotReserved) {
+		return err
+	}
+
+	// same as BF.ADD, create filter with default setting
+	if err := f.Reserve(ctx, key, f.defaultErrorRate, f.defaultCapacity); err != nil {
+		return fmt.Errorf("reserve filter of key %q: %w", key, err)
+	}
+
+	return bitmapAddScript.Run(ctx, f.RDB, []string{key}, args...).Err()
+}
+
+// Exists check item
+func (f *BitmapFilter) Exists(ctx context.Context, key string, item string) (bool, error) {
+	exists, err := f.ExistsMany(ctx, key, []string{item})
+	if err != nil {
+		return false, err
+	}
+
+	return exists[0], nil
+}
+
+// ExistsMany check items in one script call
+func (f *BitmapFilter) ExistsMany(ctx context.Context, key string, items []string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, 2*len(items))
+	for _, item := range items {
+		h1, h2 := bloomHash(item)
+		args = append(args, h1, h2)
+	}
+
+	values, err := bitmapExistsScript.Run(ctx, f.RDB, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(items) {
+		return nil, fmt.Errorf("bitmap exists script return %d results for %d items", len(values), len(items))
+	}
+
+	exists := make([]bool, len(values))
+	for i, v := range values {
+		exists[i] = v == 1
+	}
+
+	return exists, nil
+}
+
+// Info of filter read from header
+func (f *BitmapFilter) Info(ctx context.Context, key string) (Info, error) {
+	header, err := f.RDB.GetRange(ctx, key, 0, bitmapHeaderBits/8-1).Bytes()
+	if err != nil {
+		return Info{}, err
+	}
+
+	if len(header) < bitmapHeaderBits/8 {
+		return Info{}, fmt.Errorf("filter of key %q not exist", key)
+	}
+
+	return Info{
+		Capacity: int64(binary.BigEndian.Uint64(header[16:])),
+		Items:    int64(binary.BigEndian.Uint64(header[24:])),
+		Filters:  1,
+	}, nil
+}
+
+// Rename filter, in cluster mode key and newKey need to be in the same hash slot
+// https://redis.io/docs/latest/commands/rename/
+func (f *BitmapFilter) Rename(ctx context.Context, key string, newKey string) error {
+	return f.RDB.Rename(ctx, key, newKey).Err()
+}
+
+// Delete filter
+func (f *BitmapFilter) Delete(ctx context.Context, key string) error {
+	return f.RDB.Del(ctx, key).Err()
+}
+
+// Close will close connection with redis
+func (f *BitmapFilter) Close() error {
+	return f.RDB.Close()
+}
@@ -0,0 +1,103 @@
+package bloomfilter
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloom is a plain bloom filter on bit array.
+// It is not safe for concurrent use.
+type bloom struct {
+	bits []uint64
+	m    uint64 // number of bits
+	k    uint64 // number of hash functions
+
+	capacity int64
+	items    int64 // number of add, duplicated item is counted
+}
+
+// newBloom create bloom filter sized for capacity items
+// at errorRate false positive rate.
+func newBloom(errorRate float64, capacity int64) *bloom {
+	m, k := bloomSize(errorRate, capacity)
+
+	return &bloom{
+		bits:     make([]uint64, (m+63)/64),
+		m:        m,
+		k:        k,
+		capacity: max(capacity, 1),
+	}
+}
+
+// bloomSize return optimal number of bits and hash functions
+//
+//	m = -n * ln(p) / ln(2)^2
+//	k = m / n * ln(2)
+func bloomSize(errorRate float64, capacity int64) (m, k uint64) {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	if errorRate <= 0 || errorRate >= 1 {
+		errorRate = 0.01
+	}
+
+	n := float64(capacity)
+	bits := math.Ceil(-n * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Round(bits / n * math.Ln2)
+
+	if hashes < 1 {
+		hashes = 1
+	}
+
+	return uint64(bits), uint64(hashes)
+}
+
+func (b *bloom) add(item string) {
+	b.items++
+	for _, idx := range b.locations(item) {
+		b.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+func (b *bloom) exists(item string) bool {
+	for _, idx := range b.locations(item) {
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// locations return the k bit positions of item.
+func (b *bloom) locations(item string) []uint64 {
+	h1, h2 := bloomHash(item)
+	return bloomLocations(h1, h2, b.m, b.k)
+}
+
+// bloomHash split 64 bit fnv hash of item into two 32 bit hashes.
+// They are kept in 32 bit so that redis lua script (double precision number)
+// can compute the same locations as go without overflow.
+func bloomHash(item string) (h1, h2 uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(item))
+	sum := h.Sum64()
+
+	h1 = sum & 0xFFFFFFFF
+	// odd step visits more distinct positions
+	h2 = (sum >> 32) | 1
+
+	return h1, h2
+}
+
+// bloomLocations derive k locations in m bits from two hashes (Kirsch-Mitzenmacher)
+//
+//	location(i) = (h1 + i * h2) mod m
+func bloomLocations(h1, h2, m, k uint64) []uint64 {
+	locations := make([]uint64, k)
+	for i := uint64(0); i < k; i++ {
+		locations[i] = (h1 + i*h2) % m
+	}
+
+	return locations
+}
@@ -1,17 +1,73 @@
-// Package bloomfilter is the config that can use redis as bloomfilter
+// Package bloomfilter provides bloom filter backed by RedisBloom,
+// plain redis bitmap or local memory
 package bloomfilter
 
-// Config is the config of cache
+import (
+	"strings"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/pkg/redisclient"
+)
+
+// Backend is where the bloom filter is stored
+type Backend string
+
+const (
+	// BackendRedis use BF.* commands of RedisBloom module
+	BackendRedis Backend = "redis"
+	// BackendBitmap use SETBIT / GETBIT on plain redis,
+	// for redis without RedisBloom module
+	BackendBitmap Backend = "bitmap"
+	// BackendMemory store filter in memory of current process,
+	// optionally persisted by file snapshot
+	BackendMemory Backend = "memory"
+)
+
+// Type is the data structure of filter
+type Type string
+
+const (
+	// TypeBloom is bloom filter, item can not be removed
+	TypeBloom Type = "bloom"
+	// TypeCuckoo is cuckoo filter, item can be removed (ex: deleted link).
+	// It is supported by redis and memory backend.
+	TypeCuckoo Type = "cuckoo"
+)
+
+// Config is the config of bloom filter
 type Config struct {
-	RedisConnectURL            string  `env:"REDIS_CONNECT_URL, default=localhost:6379"`
-	RedisPassword              string  `env:"REDIS_PASSWORD"`
-	RedisBloomFilterDB         int     `env:"REDIS_BLOOM_FILTER_DB, default=1"`
-	RedisBloomFilterErrorRate  float64 `env:"REDIS_BLOOM_FILTER_ERROR_RATE, default=0.001"`
-	RedisBloomFilterCapacity   int64   `env:"REDIS_BLOOM_FILTER_CAPACITY, default=1000"`
-	RedisSerializationProtocol int     `env:"REDIS_SERIALIZATION_PROTOCAL, default=2"`
+	Backend                   Backend `env:"BLOOM_FILTER_BACKEND, default=redis"`
+	Type                      Type    `env:"BLOOM_FILTER_TYPE, default=bloom"`
+	Redis                     redisclient.Config
+	RedisBloomFilterDB        int     `env:"REDIS_BLOOM_FILTER_DB, default=1"`
+	RedisBloomFilterErrorRate float64 `env:"REDIS_BLOOM_FILTER_ERROR_RATE, default=0.001"`
+	RedisBloomFilterCapacity  int64   `env:"REDIS_BLOOM_FILTER_CAPACITY, default=1000"`
+
+	// SnapshotPath is the file memory backend saves to and loads from,
+	// empty means no snapshot.
+	SnapshotPath     string        `env:"BLOOM_FILTER_SNAPSHOT_PATH"`
+	SnapshotInterval time.Duration `env:"BLOOM_FILTER_SNAPSHOT_INTERVAL, default=1m"`
 }
 
-// BloomFilterConfig return the config of cache
+// BloomFilterConfig return the config of bloom filter
 func (c *Config) BloomFilterConfig() *Config {
 	return c
 }
+
+// FilterBackend return normalized Backend, empty means BackendRedis
+func (c *Config) FilterBackend() Backend {
+	b := Backend(strings.ToLower(strings.TrimSpace(string(c.Backend))))
+	if b == "" {
+		return BackendRedis
+	}
+	return b
+}
+
+// FilterType return normalized Type, empty means TypeBloom
+func (c *Config) FilterType() Type {
+	t := Type(strings.ToLower(strings.TrimSpace(string(c.Type))))
+	if t == "" {
+		return TypeBloom
+	}
+	return t
+}
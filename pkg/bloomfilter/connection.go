@@ -2,40 +2,56 @@ package bloomfilter
 
 import (
 	"context"
-
-	"github.com/redis/go-redis/v9"
+	"fmt"
 
 	"github.com/TinyMurky/tinyurl/pkg/logging"
+	"github.com/TinyMurky/tinyurl/pkg/redisclient"
 )
 
-// BloomFilter is the pool of database Conn
-type BloomFilter struct {
-	RDB *redis.Client
-}
-
-// NewFromEnv sets up the redis connections using the configuration in the
+// NewFromEnv sets up the bloom filter using the configuration in the
 // process's environment variables. This should be called just once per server
 // instance.
-func NewFromEnv(ctx context.Context, cfg *Config) (*BloomFilter, error) {
+func NewFromEnv(ctx context.Context, cfg *Config) (Filter, error) {
 	logger := logging.FromContext(ctx)
 
-	rdbOpt := redis.Options{
-		Addr:     cfg.RedisConnectURL,
-		Password: cfg.RedisPassword,
-		DB:       cfg.RedisBloomFilterDB,
-		Protocol: cfg.RedisSerializationProtocol,
+	filterType := cfg.FilterType()
+	if filterType != TypeBloom && filterType != TypeCuckoo {
+		return nil, fmt.Errorf("unknown BLOOM_FILTER_TYPE %q", cfg.Type)
 	}
 
-	rdb := redis.NewClient(&rdbOpt)
-
-	logger.Infof("Open redis cache at URL: %s", rdbOpt.Addr)
-
-	return &BloomFilter{
-		RDB: rdb,
-	}, nil
-}
-
-// Close will close connection with redis
-func (c *BloomFilter) Close() error {
-	return c.RDB.Close()
+	switch cfg.FilterBackend() {
+	case BackendRedis:
+		rdb, err := redisclient.New(ctx, &cfg.Redis, cfg.RedisBloomFilterDB)
+		if err != nil {
+			return nil, fmt.Errorf("redisclient.New: %w", err)
+		}
+		if filterType == TypeCuckoo {
+			return NewRedisCuckooFilter(rdb), nil
+		}
+		return NewRedisFilter(rdb), nil
+	case BackendBitmap:
+		if filterType == TypeCuckoo {
+			return nil, fmt.Errorf("BLOOM_FILTER_TYPE %q is not supported by bitmap backend", cfg.Type)
+		}
+		rdb, err := redisclient.New(ctx, &cfg.Redis, cfg.RedisBloomFilterDB)
+		if err != nil {
+			return nil, fmt.Errorf("redisclient.New: %w", err)
+		}
+		return NewBitmapFilter(rdb, cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity), nil
+	case BackendMemory:
+		logger.Infof("Open memory %s filter", filterType)
+		if filterType == TypeCuckoo {
+			if cfg.SnapshotPath != "" {
+				logger.Warn("BLOOM_FILTER_SNAPSHOT_PATH is ignored by memory cuckoo filter")
+			}
+			return NewMemoryCuckooFilter(cfg.RedisBloomFilterCapacity), nil
+		}
+		f := NewMemoryFilter(cfg.RedisBloomFilterErrorRate, cfg.RedisBloomFilterCapacity)
+		if cfg.SnapshotPath != "" {
+			f.EnableSnapshot(ctx, cfg.SnapshotPath, cfg.SnapshotInterval)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unknown BLOOM_FILTER_BACKEND %q", cfg.Backend)
+	}
 }
@@ -0,0 +1,196 @@
+package bloomfilter
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+)
+
+const (
+	// cuckooBucketSize and 8 bit fingerprint are the defaults of RedisBloom,
+	// so memory and redis cuckoo filter have the same false positive rate.
+	cuckooBucketSize = 2
+	// cuckooMaxKicks is the number of relocation before a table is full
+	cuckooMaxKicks = 500
+	// cuckooExpansion is the size of new sub table relative to the last one
+	cuckooExpansion = 2
+)
+
+// cuckoo is a cuckoo filter with 8 bit fingerprint, unlike bloom filter
+// an item can be deleted. When a table is full, a larger table is appended
+// (like RedisBloom), and lookup checks all tables.
+// It is not safe for concurrent use.
+type cuckoo struct {
+	tables   []*cuckooTable
+	capacity int64
+	items    int64 // number of add minus number of delete
+}
+
+type cuckooTable struct {
+	buckets [][cuckooBucketSize]uint8 // 0 means empty slot
+	mask    uint64                    // number of buckets - 1
+}
+
+// cuckooKick is one relocation, it is kept to undo a failed insert
+type cuckooKick struct {
+	bucket uint64
+	slot   int
+	fp     uint8
+}
+
+// newCuckoo create cuckoo filter for capacity items
+func newCuckoo(capacity int64) *cuckoo {
+	capacity = max(capacity, 1)
+
+	return &cuckoo{
+		tables:   []*cuckooTable{newCuckooTable(uint64(capacity))},
+		capacity: capacity,
+	}
+}
+
+func newCuckooTable(capacity uint64) *cuckooTable {
+	numBuckets := uint64(1)
+	for numBuckets*cuckooBucketSize < capacity {
+		numBuckets <<= 1
+	}
+
+	return &cuckooTable{
+		buckets: make([][cuckooBucketSize]uint8, numBuckets),
+		mask:    numBuckets - 1,
+	}
+}
+
+// cuckooHash return fingerprint (never 0) and hash of item
+func cuckooHash(item string) (fp uint8, h uint64) {
+	hash := fnv.New64a()
+	hash.Write([]byte(item))
+	h = hash.Sum64()
+
+	return uint8((h>>32)%255 + 1), h
+}
+
+// altIndex is the other bucket of fingerprint in bucket i,
+// altIndex(altIndex(i)) == i
+func (t *cuckooTable) altIndex(i uint64, fp uint8) uint64 {
+	return (i ^ (uint64(fp) * 0x5bd1e995)) & t.mask
+}
+
+func (c *cuckoo) add(item string) {
+	fp, h := cuckooHash(item)
+	c.items++
+
+	for _, t := range c.tables {
+		if t.insert(fp, h&t.mask) {
+			return
+		}
+	}
+
+	last := c.tables[len(c.tables)-1]
+	t := newCuckooTable(uint64(len(last.buckets)) * cuckooBucketSize * cuckooExpansion)
+	c.tables = append(c.tables, t)
+	t.insert(fp, h&t.mask)
+}
+
+func (c *cuckoo) exists(item string) bool {
+	fp, h := cuckooHash(item)
+
+	for _, t := range c.tables {
+		i1 := h & t.mask
+		if t.has(i1, fp) || t.has(t.altIndex(i1, fp), fp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remove one copy of item, it returns false if item is not found.
+// Removing an item that was never added may remove another item
+// with the same fingerprint and cause false negative.
+func (c *cuckoo) remove(item string) bool {
+	fp, h := cuckooHash(item)
+
+	// newest table first, same as RedisBloom
+	for i := len(c.tables) - 1; i >= 0; i-- {
+		t := c.tables[i]
+		i1 := h & t.mask
+		if t.delete(i1, fp) || t.delete(t.altIndex(i1, fp), fp) {
+			c.items--
+			return true
+		}
+	}
+
+	return false
+}
+
+// totalCapacity is the number of slots of all tables
+func (c *cuckoo) totalCapacity() int64 {
+	var total int64
+	for _, t := range c.tables {
+		total += int64(len(t.buckets)) * cuckooBucketSize
+	}
+	return total
+}
+
+// insert fp into bucket i or its alternate, relocating other fingerprints
+// if both are full. Table is unchanged if insert failed.
+func (t *cuckooTable) insert(fp uint8, i uint64) bool {
+	alt := t.altIndex(i, fp)
+	if t.put(i, fp) || t.put(alt, fp) {
+		return true
+	}
+
+	kicks := make([]cuckooKick, 0, cuckooMaxKicks)
+	if rand.IntN(2) == 0 {
+		i = alt
+	}
+
+	for range cuckooMaxKicks {
+		slot := rand.IntN(cuckooBucketSize)
+		victim := t.buckets[i][slot]
+		t.buckets[i][slot] = fp
+		kicks = append(kicks, cuckooKick{bucket: i, slot: slot, fp: victim})
+
+		fp = victim
+		i = t.altIndex(i, fp)
+		if t.put(i, fp) {
+			return true
+		}
+	}
+
+	// undo relocation so that no fingerprint is lost
+	for j := len(kicks) - 1; j >= 0; j-- {
+		k := kicks[j]
+		t.buckets[k.bucket][k.slot] = k.fp
+	}
+
+	return false
+}
+
+func (t *cuckooTable) put(i uint64, fp uint8) bool {
+	for slot, v := range t.buckets[i] {
+		if v == 0 {
+			t.buckets[i][slot] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (t *cuckooTable) has(i uint64, fp uint8) bool {
+	for _, v := range t.buckets[i] {
+		if v == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *cuckooTable) delete(i uint64, fp uint8) bool {
+	for slot, v := range t.buckets[i] {
+		if v == fp {
+			t.buckets[i][slot] = 0
+			return true
+		}
+	}
+	return false
+}
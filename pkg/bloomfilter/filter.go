@@ -0,0 +1,106 @@
+package bloomfilter
+
+import (
+	"context"
+	"math"
+)
+
+// Filter is a probabilistic set, it can tell an item is
+// "definitely not exist" or "might exist".
+// It is implemented by RedisFilter, BitmapFilter and MemoryFilter (bloom filter),
+// and by RedisCuckooFilter and MemoryCuckooFilter (cuckoo filter).
+type Filter interface {
+	// Reserve create filter of key with error rate and capacity.
+	// It does nothing if filter of key already exist.
+	Reserve(ctx context.Context, key string, errorRate float64, capacity int64) error
+
+	// Add item to filter of key
+	Add(ctx context.Context, key string, item string) error
+
+	// AddMany add items to filter of key in one round trip
+	AddMany(ctx context.Context, key string, items []string) error
+
+	// Exists return false if item is definitely not in filter of key
+	Exists(ctx context.Context, key string, item string) (bool, error)
+
+	// ExistsMany is Exists of items in one round trip,
+	// result is in the same order as items
+	ExistsMany(ctx context.Context, key string, items []string) ([]bool, error)
+
+	// Info return the capacity and usage of filter of key
+	Info(ctx context.Context, key string) (Info, error)
+
+	// Rename atomically replace filter of newKey with filter of key
+	Rename(ctx context.Context, key string, newKey string) error
+
+	// Delete remove filter of key, key not exist is ignored
+	Delete(ctx context.Context, key string) error
+
+	// Close release the resource of filter
+	Close() error
+}
+
+// Remover is a Filter that item can be removed from, it is implemented by cuckoo filters.
+//
+// Only remove item that was added, otherwise other item with the same
+// fingerprint may be removed and become a false negative.
+type Remover interface {
+	Filter
+
+	// Remove one copy of item from filter of key,
+	// item not exist is ignored
+	Remove(ctx context.Context, key string, item string) error
+}
+
+// Info is the capacity and usage of a filter
+type Info struct {
+	// Capacity is the number of items filter can hold at its error rate
+	Capacity int64
+	// Items is the number of items inserted
+	Items int64
+	// Filters is the number of sub filters,
+	// more than 1 means filter has scaled up after capacity was exceeded.
+	Filters int64
+}
+
+// FillRatio return Items / Capacity
+func (i Info) FillRatio() float64 {
+	if i.Capacity <= 0 {
+		return 0
+	}
+	return float64(i.Items) / float64(i.Capacity)
+}
+
+// EstimateFalsePositiveRate estimate false positive rate of filter sized for
+// capacity at errorRate after items are inserted:
+//
+//	(1 - e^(-k * n / m))^k
+//
+// It treats the filter as a single bloom filter, sub filters from scaling up
+// are not taken into account.
+func EstimateFalsePositiveRate(errorRate float64, capacity int64, items int64) float64 {
+	if items <= 0 {
+		return 0
+	}
+
+	m, k := bloomSize(errorRate, capacity)
+	kf := float64(k)
+
+	return math.Pow(1-math.Exp(-kf*float64(items)/float64(m)), kf)
+}
+
+// EstimateCuckooFalsePositiveRate estimate false positive rate of cuckoo filter
+// with capacity slots after items are inserted. It is the upper bound
+//
+//	2 * b * (items / capacity) / 2^f
+//
+// with bucket size b = 2 and fingerprint of f = 8 bits,
+// the defaults of RedisBloom and the one of MemoryCuckooFilter.
+func EstimateCuckooFalsePositiveRate(capacity int64, items int64) float64 {
+	if items <= 0 || capacity <= 0 {
+		return 0
+	}
+
+	load := min(float64(items)/float64(capacity), 1)
+	return 2 * cuckooBucketSize * load / 256
+}
@@ -0,0 +1,165 @@
+package bloomfilter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryFilter is bloom filter stored in memory of current process.
+// Content is not shared between instances, and is lost on restart
+// unless snapshot is enabled (see EnableSnapshot).
+// Unlike RedisBloom, it does not scale up when capacity is exceeded,
+// the false positive rate grows instead.
+type MemoryFilter struct {
+	defaultErrorRate float64
+	defaultCapacity  int64
+
+	mu           sync.RWMutex
+	filters      map[string]*bloom
+	snapshotPath string
+	closed       bool
+
+	// snapshotMu serialize saving snapshot
+	snapshotMu sync.Mutex
+}
+
+var _ Filter = (*MemoryFilter)(nil)
+
+// NewMemoryFilter create memory filter, filter of key that Add before
+// Reserve is created with defaultErrorRate and defaultCapacity.
+func NewMemoryFilter(defaultErrorRate float64, defaultCapacity int64) *MemoryFilter {
+	return &MemoryFilter{
+		defaultErrorRate: defaultErrorRate,
+		defaultCapacity:  defaultCapacity,
+		filters:          make(map[string]*bloom),
+	}
+}
+
+// Reserve filter
+func (f *MemoryFilter) Reserve(_ context.Context, key string, errorRate float64, capacity int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.filters[key]; !ok {
+		f.filters[key] = newBloom(errorRate, capacity)
+	}
+
+	return nil
+}
+
+// Add item
+func (f *MemoryFilter) Add(_ context.Context, key string, item string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.filters[key]
+	if !ok {
+		b = newBloom(f.defaultErrorRate, f.defaultCapacity)
+		f.filters[key] = b
+	}
+
+	b.add(item)
+	return nil
+}
+
+// AddMany add items
+func (f *MemoryFilter) AddMany(ctx context.Context, key string, items []string) error {
+	for _, item := range items {
+		if err := f.Add(ctx, key, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists check item
+func (f *MemoryFilter) Exists(_ context.Context, key string, item string) (bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	b, ok := f.filters[key]
+	if !ok {
+		return false, nil
+	}
+
+	return b.exists(item), nil
+}
+
+// ExistsMany check items
+func (f *MemoryFilter) ExistsMany(_ context.Context, key string, items []string) ([]bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	exists := make([]bool, len(items))
+
+	b, ok := f.filters[key]
+	if !ok {
+		return exists, nil
+	}
+
+	for i, item := range items {
+		exists[i] = b.exists(item)
+	}
+	return exists, nil
+}
+
+// Info of filter
+func (f *MemoryFilter) Info(_ context.Context, key string) (Info, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	b, ok := f.filters[key]
+	if !ok {
+		return Info{}, fmt.Errorf("filter of key %q not exist", key)
+	}
+
+	return Info{
+		Capacity: b.capacity,
+		Items:    b.items,
+		Filters:  1,
+	}, nil
+}
+
+// Rename filter
+func (f *MemoryFilter) Rename(_ context.Context, key string, newKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.filters[key]
+	if !ok {
+		return fmt.Errorf("filter of key %q not exist", key)
+	}
+
+	f.filters[newKey] = b
+	delete(f.filters, key)
+
+	return nil
+}
+
+// Delete filter
+func (f *MemoryFilter) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.filters, key)
+	return nil
+}
+
+// Close save snapshot if it is enabled, then drop all filters
+func (f *MemoryFilter) Close() error {
+	f.snapshotMu.Lock()
+	defer f.snapshotMu.Unlock()
+
+	err := f.saveSnapshotLocked()
+	if err != nil {
+		err = fmt.Errorf("save snapshot: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	f.filters = make(map[string]*bloom)
+	return err
+}
@@ -0,0 +1,158 @@
+package bloomfilter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryCuckooFilter is cuckoo filter stored in memory of current process.
+// Content is lost on restart and not shared between instances.
+type MemoryCuckooFilter struct {
+	defaultCapacity int64
+
+	mu      sync.RWMutex
+	filters map[string]*cuckoo
+}
+
+var _ Remover = (*MemoryCuckooFilter)(nil)
+
+// NewMemoryCuckooFilter create memory cuckoo filter, filter of key that Add before
+// Reserve is created with defaultCapacity.
+func NewMemoryCuckooFilter(defaultCapacity int64) *MemoryCuckooFilter {
+	return &MemoryCuckooFilter{
+		defaultCapacity: defaultCapacity,
+		filters:         make(map[string]*cuckoo),
+	}
+}
+
+// Reserve filter, errorRate is ignored because it is decided by fingerprint size
+func (f *MemoryCuckooFilter) Reserve(_ context.Context, key string, _ float64, capacity int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.filters[key]; !ok {
+		f.filters[key] = newCuckoo(capacity)
+	}
+
+	return nil
+}
+
+// Add item
+func (f *MemoryCuckooFilter) Add(_ context.Context, key string, item string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.filters[key]
+	if !ok {
+		c = newCuckoo(f.defaultCapacity)
+		f.filters[key] = c
+	}
+
+	c.add(item)
+	return nil
+}
+
+// AddMany add items
+func (f *MemoryCuckooFilter) AddMany(ctx context.Context, key string, items []string) error {
+	for _, item := range items {
+		if err := f.Add(ctx, key, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists check item
+func (f *MemoryCuckooFilter) Exists(_ context.Context, key string, item string) (bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	c, ok := f.filters[key]
+	if !ok {
+		return false, nil
+	}
+
+	return c.exists(item), nil
+}
+
+// ExistsMany check items
+func (f *MemoryCuckooFilter) ExistsMany(_ context.Context, key string, items []string) ([]bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	exists := make([]bool, len(items))
+
+	c, ok := f.filters[key]
+	if !ok {
+		return exists, nil
+	}
+
+	for i, item := range items {
+		exists[i] = c.exists(item)
+	}
+	return exists, nil
+}
+
+// Remove item
+func (f *MemoryCuckooFilter) Remove(_ context.Context, key string, item string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c, ok := f.filters[key]; ok {
+		c.remove(item)
+	}
+
+	return nil
+}
+
+// Info of filter, capacity is the number of slots of all sub filters
+func (f *MemoryCuckooFilter) Info(_ context.Context, key string) (Info, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	c, ok := f.filters[key]
+	if !ok {
+		return Info{}, fmt.Errorf("filter of key %q not exist", key)
+	}
+
+	return Info{
+		Capacity: c.totalCapacity(),
+		Items:    c.items,
+		Filters:  int64(len(c.tables)),
+	}, nil
+}
+
+// Rename filter
+func (f *MemoryCuckooFilter) Rename(_ context.Context, key string, newKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.filters[key]
+	if !ok {
+		return fmt.Errorf("filter of key %q not exist", key)
+	}
+
+	f.filters[newKey] = c
+	delete(f.filters, key)
+
+	return nil
+}
+
+// Delete filter
+func (f *MemoryCuckooFilter) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.filters, key)
+	return nil
+}
+
+// Close drop all filters
+func (f *MemoryCuckooFilter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.filters = make(map[string]*cuckoo)
+	return nil
+}
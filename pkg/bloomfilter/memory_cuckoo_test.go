@@ -0,0 +1,104 @@
+package bloomfilter
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestMemoryCuckooFilter(t *testing.T) {
+	ctx := context.Background()
+	f := NewMemoryCuckooFilter(100)
+
+	const (
+		key      = "key"
+		capacity = 1000
+	)
+
+	if err := f.Reserve(ctx, key, 0, capacity); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	items := make([]string, capacity)
+	for i := range items {
+		items[i] = strconv.Itoa(i)
+	}
+
+	if err := f.AddMany(ctx, key, items); err != nil {
+		t.Fatalf("AddMany: %v", err)
+	}
+
+	// no false negative
+	for _, item := range items {
+		if ok, _ := f.Exists(ctx, key, item); !ok {
+			t.Fatalf("item %s added but not exist", item)
+		}
+	}
+
+	// removed item is gone, others are kept
+	for _, item := range items[:capacity/2] {
+		if err := f.Remove(ctx, key, item); err != nil {
+			t.Fatalf("Remove: %v", err)
+		}
+	}
+
+	stillExist := 0
+	for _, item := range items[:capacity/2] {
+		if ok, _ := f.Exists(ctx, key, item); ok {
+			stillExist++
+		}
+	}
+
+	// fingerprint collision can keep a few removed items
+	if rate := float64(stillExist) / (capacity / 2); rate > 0.05 {
+		t.Errorf("%d of %d removed items still exist", stillExist, capacity/2)
+	}
+
+	for _, item := range items[capacity/2:] {
+		if ok, _ := f.Exists(ctx, key, item); !ok {
+			t.Fatalf("item %s not removed but not exist", item)
+		}
+	}
+
+	info, err := f.Info(ctx, key)
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.Items != capacity/2 {
+		t.Errorf("expect %d items, got %d", capacity/2, info.Items)
+	}
+}
+
+func TestMemoryCuckooFilter_Expand(t *testing.T) {
+	ctx := context.Background()
+	f := NewMemoryCuckooFilter(16)
+
+	const n = 1000
+	for i := 0; i < n; i++ {
+		_ = f.Add(ctx, "key", strconv.Itoa(i))
+	}
+
+	for i := 0; i < n; i++ {
+		if ok, _ := f.Exists(ctx, "key", strconv.Itoa(i)); !ok {
+			t.Fatalf("item %d added but not exist", i)
+		}
+	}
+
+	info, err := f.Info(ctx, "key")
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.Filters <= 1 || info.Capacity < n {
+		t.Errorf("expect filter to expand, got %+v", info)
+	}
+}
+
+func TestEstimateCuckooFalsePositiveRate(t *testing.T) {
+	if got := EstimateCuckooFalsePositiveRate(1000, 0); got != 0 {
+		t.Errorf("empty filter: expect 0, got %f", got)
+	}
+
+	if got := EstimateCuckooFalsePositiveRate(1000, 1000); got != 4.0/256 {
+		t.Errorf("full filter: expect %f, got %f", 4.0/256, got)
+	}
+}
@@ -0,0 +1,139 @@
+package bloomfilter
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestMemoryFilter(t *testing.T) {
+	ctx := context.Background()
+	f := NewMemoryFilter(0.01, 100)
+
+	const (
+		key       = "key"
+		capacity  = 1000
+		errorRate = 0.01
+	)
+
+	if err := f.Reserve(ctx, key, errorRate, capacity); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	for i := 0; i < capacity; i++ {
+		if err := f.Add(ctx, key, strconv.Itoa(i)); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	// no false negative
+	for i := 0; i < capacity; i++ {
+		ok, err := f.Exists(ctx, key, strconv.Itoa(i))
+		if err != nil {
+			t.Fatalf("Exists: %v", err)
+		}
+		if !ok {
+			t.Fatalf("item %d added but not exist", i)
+		}
+	}
+
+	// false positive rate is close to error rate
+	falsePositive := 0
+	const probes = 10000
+	for i := capacity; i < capacity+probes; i++ {
+		if ok, _ := f.Exists(ctx, key, strconv.Itoa(i)); ok {
+			falsePositive++
+		}
+	}
+
+	if rate := float64(falsePositive) / probes; rate > errorRate*3 {
+		t.Errorf("false positive rate %f is much higher than %f", rate, errorRate)
+	}
+
+	// other key is independent
+	if ok, _ := f.Exists(ctx, "other", "0"); ok {
+		t.Error("item should not exist in other key")
+	}
+}
+
+func TestMemoryFilter_ReserveKeepExisting(t *testing.T) {
+	ctx := context.Background()
+	f := NewMemoryFilter(0.01, 100)
+
+	_ = f.Add(ctx, "key", "a")
+	_ = f.Reserve(ctx, "key", 0.01, 100)
+
+	if ok, _ := f.Exists(ctx, "key", "a"); !ok {
+		t.Error("Reserve should not reset existing filter")
+	}
+}
+
+func TestMemoryFilter_Info(t *testing.T) {
+	ctx := context.Background()
+	f := NewMemoryFilter(0.01, 100)
+
+	_ = f.Reserve(ctx, "key", 0.01, 100)
+	for i := 0; i < 50; i++ {
+		_ = f.Add(ctx, "key", strconv.Itoa(i))
+	}
+
+	info, err := f.Info(ctx, "key")
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+
+	if info.Capacity != 100 || info.Items != 50 {
+		t.Errorf("expect capacity 100 and items 50, got %+v", info)
+	}
+
+	if got := info.FillRatio(); got != 0.5 {
+		t.Errorf("FillRatio: expect 0.5, got %f", got)
+	}
+}
+
+func TestEstimateFalsePositiveRate(t *testing.T) {
+	const errorRate = 0.01
+
+	if got := EstimateFalsePositiveRate(errorRate, 1000, 0); got != 0 {
+		t.Errorf("empty filter: expect 0, got %f", got)
+	}
+
+	// at capacity it is close to error rate
+	atCapacity := EstimateFalsePositiveRate(errorRate, 1000, 1000)
+	if atCapacity < errorRate*0.8 || atCapacity > errorRate*1.2 {
+		t.Errorf("at capacity: expect about %f, got %f", errorRate, atCapacity)
+	}
+
+	// over capacity it is worse
+	if got := EstimateFalsePositiveRate(errorRate, 1000, 3000); got <= atCapacity {
+		t.Errorf("over capacity: expect more than %f, got %f", atCapacity, got)
+	}
+}
+
+func TestMemoryFilter_ExistsMany(t *testing.T) {
+	ctx := context.Background()
+	f := NewMemoryFilter(0.001, 100)
+
+	got, err := f.ExistsMany(ctx, "key", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("ExistsMany: %v", err)
+	}
+	if len(got) != 2 || got[0] || got[1] {
+		t.Fatalf("expect all false before Reserve, got %v", got)
+	}
+
+	if err := f.Reserve(ctx, "key", 0.001, 100); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := f.Add(ctx, "key", "b"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	got, err = f.ExistsMany(ctx, "key", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("ExistsMany: %v", err)
+	}
+	if want := []bool{false, true, false}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("ExistsMany = %v, want %v", got, want)
+	}
+}
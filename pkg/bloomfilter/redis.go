@@ -0,0 +1,108 @@
+package bloomfilter
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisFilter is bloom filter of RedisBloom module (BF.* commands).
+// RDB can be standalone, sentinel or cluster client.
+type RedisFilter struct {
+	RDB redis.UniversalClient
+}
+
+var _ Filter = (*RedisFilter)(nil)
+
+// NewRedisFilter create filter on redis client
+func NewRedisFilter(rdb redis.UniversalClient) *RedisFilter {
+	return &RedisFilter{
+		RDB: rdb,
+	}
+}
+
+// Reserve filter
+// https://redis.io/docs/latest/commands/bf.reserve/
+func (f *RedisFilter) Reserve(ctx context.Context, key string, errorRate float64, capacity int64) error {
+	err := f.RDB.BFReserve(ctx, key, errorRate, capacity).Err()
+
+	if err != nil && strings.Contains(err.Error(), "item exists") {
+		return nil
+	}
+
+	return err
+}
+
+// Add item
+// https://redis.io/docs/latest/commands/bf.add/
+func (f *RedisFilter) Add(ctx context.Context, key string, item string) error {
+	return f.RDB.BFAdd(ctx, key, item).Err()
+}
+
+// AddMany add items
+// https://redis.io/docs/latest/commands/bf.madd/
+func (f *RedisFilter) AddMany(ctx context.Context, key string, items []string) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	elements := make([]any, len(items))
+	for i, item := range items {
+		elements[i] = item
+	}
+
+	return f.RDB.BFMAdd(ctx, key, elements...).Err()
+}
+
+// Exists check item
+// https://redis.io/docs/latest/commands/bf.exists/
+func (f *RedisFilter) Exists(ctx context.Context, key string, item string) (bool, error) {
+	return f.RDB.BFExists(ctx, key, item).Result()
+}
+
+// ExistsMany check items
+// https://redis.io/docs/latest/commands/bf.mexists/
+func (f *RedisFilter) ExistsMany(ctx context.Context, key string, items []string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	elements := make([]any, len(items))
+	for i, item := range items {
+		elements[i] = item
+	}
+
+	return f.RDB.BFMExists(ctx, key, elements...).Result()
+}
+
+// Info of filter
+// https://redis.io/docs/latest/commands/bf.info/
+func (f *RedisFilter) Info(ctx context.Context, key string) (Info, error) {
+	info, err := f.RDB.BFInfo(ctx, key).Result()
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Info{
+		Capacity: info.Capacity,
+		Items:    info.ItemsInserted,
+		Filters:  info.Filters,
+	}, nil
+}
+
+// Rename filter, in cluster mode key and newKey need to be in the same hash slot
+// https://redis.io/docs/latest/commands/rename/
+func (f *RedisFilter) Rename(ctx context.Context, key string, newKey string) error {
+	return f.RDB.Rename(ctx, key, newKey).Err()
+}
+
+// Delete filter
+func (f *RedisFilter) Delete(ctx context.Context, key string) error {
+	return f.RDB.Del(ctx, key).Err()
+}
+
+// Close will close connection with redis
+func (f *RedisFilter) Close() error {
+	return f.RDB.Close()
+}
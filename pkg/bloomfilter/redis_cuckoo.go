@@ -0,0 +1,120 @@
+package bloomfilter
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCuckooFilter is cuckoo filter of RedisBloom module (CF.* commands).
+// RDB can be standalone, sentinel or cluster client.
+type RedisCuckooFilter struct {
+	RDB redis.UniversalClient
+}
+
+var _ Remover = (*RedisCuckooFilter)(nil)
+
+// NewRedisCuckooFilter create filter on redis client
+func NewRedisCuckooFilter(rdb redis.UniversalClient) *RedisCuckooFilter {
+	return &RedisCuckooFilter{
+		RDB: rdb,
+	}
+}
+
+// Reserve filter, errorRate is ignored because it is decided by fingerprint size
+// https://redis.io/docs/latest/commands/cf.reserve/
+func (f *RedisCuckooFilter) Reserve(ctx context.Context, key string, _ float64, capacity int64) error {
+	err := f.RDB.CFReserve(ctx, key, capacity).Err()
+
+	if err != nil && strings.Contains(err.Error(), "item exists") {
+		return nil
+	}
+
+	return err
+}
+
+// Add item
+// https://redis.io/docs/latest/commands/cf.add/
+func (f *RedisCuckooFilter) Add(ctx context.Context, key string, item string) error {
+	return f.RDB.CFAdd(ctx, key, item).Err()
+}
+
+// AddMany add items
+// https://redis.io/docs/latest/commands/cf.insert/
+func (f *RedisCuckooFilter) AddMany(ctx context.Context, key string, items []string) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	elements := make([]any, len(items))
+	for i, item := range items {
+		elements[i] = item
+	}
+
+	return f.RDB.CFInsert(ctx, key, nil, elements...).Err()
+}
+
+// Exists check item
+// https://redis.io/docs/latest/commands/cf.exists/
+func (f *RedisCuckooFilter) Exists(ctx context.Context, key string, item string) (bool, error) {
+	return f.RDB.CFExists(ctx, key, item).Result()
+}
+
+// ExistsMany check items
+// https://redis.io/docs/latest/commands/cf.mexists/
+func (f *RedisCuckooFilter) ExistsMany(ctx context.Context, key string, items []string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	elements := make([]any, len(items))
+	for i, item := range items {
+		elements[i] = item
+	}
+
+	return f.RDB.CFMExists(ctx, key, elements...).Result()
+}
+
+// Remove item
+// https://redis.io/docs/latest/commands/cf.del/
+func (f *RedisCuckooFilter) Remove(ctx context.Context, key string, item string) error {
+	err := f.RDB.CFDel(ctx, key, item).Err()
+
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return nil
+	}
+
+	return err
+}
+
+// Info of filter, capacity is the number of slots
+// https://redis.io/docs/latest/commands/cf.info/
+func (f *RedisCuckooFilter) Info(ctx context.Context, key string) (Info, error) {
+	info, err := f.RDB.CFInfo(ctx, key).Result()
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Info{
+		Capacity: info.NumBuckets * info.BucketSize,
+		Items:    info.NumItemsInserted,
+		Filters:  info.NumFilters,
+	}, nil
+}
+
+// Rename filter, in cluster mode key and newKey need to be in the same hash slot
+// https://redis.io/docs/latest/commands/rename/
+func (f *RedisCuckooFilter) Rename(ctx context.Context, key string, newKey string) error {
+	return f.RDB.Rename(ctx, key, newKey).Err()
+}
+
+// Delete filter
+func (f *RedisCuckooFilter) Delete(ctx context.Context, key string) error {
+	return f.RDB.Del(ctx, key).Err()
+}
+
+// Close will close connection with redis
+func (f *RedisCuckooFilter) Close() error {
+	return f.RDB.Close()
+}
@@ -0,0 +1,188 @@
+package bloomfilter
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// snapshotVersion is bumped when layout of bits (ex: hash function) changed,
+// snapshot of other version is ignored and filter need to be rebuilt.
+const snapshotVersion = 1
+
+type memorySnapshot struct {
+	Version int
+	Filters map[string]bloomSnapshot
+}
+
+type bloomSnapshot struct {
+	Bits     []uint64
+	M        uint64
+	K        uint64
+	Capacity int64
+	Items    int64
+}
+
+// WriteSnapshot write all filters to w
+func (f *MemoryFilter) WriteSnapshot(w io.Writer) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	snapshot := memorySnapshot{
+		Version: snapshotVersion,
+		Filters: make(map[string]bloomSnapshot, len(f.filters)),
+	}
+
+	for key, b := range f.filters {
+		snapshot.Filters[key] = bloomSnapshot{
+			Bits:     b.bits,
+			M:        b.m,
+			K:        b.k,
+			Capacity: b.capacity,
+			Items:    b.items,
+		}
+	}
+
+	return gob.NewEncoder(w).Encode(snapshot)
+}
+
+// ReadSnapshot replace all filters with snapshot read from r
+func (f *MemoryFilter) ReadSnapshot(r io.Reader) error {
+	var snapshot memorySnapshot
+	if err := gob.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("snapshot version %d is not supported, expect %d", snapshot.Version, snapshotVersion)
+	}
+
+	filters := make(map[string]*bloom, len(snapshot.Filters))
+	for key, s := range snapshot.Filters {
+		if s.M == 0 || uint64(len(s.Bits)) != (s.M+63)/64 {
+			return fmt.Errorf("snapshot of key %q is corrupted", key)
+		}
+
+		filters[key] = &bloom{
+			bits:     s.Bits,
+			m:        s.M,
+			k:        s.K,
+			capacity: s.Capacity,
+			items:    s.Items,
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.filters = filters
+	return nil
+}
+
+// SaveSnapshot write snapshot to file of path.
+// It writes to temp file then rename, so file of path is never half written.
+func (f *MemoryFilter) SaveSnapshot(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := f.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot replace all filters with snapshot in file of path.
+// Error wraps os.ErrNotExist if there is no snapshot yet.
+func (f *MemoryFilter) LoadSnapshot(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return f.ReadSnapshot(file)
+}
+
+// EnableSnapshot load filters from snapshot file of path and
+// save them back every interval until ctx is done, and on Close.
+// Missing or unreadable snapshot is logged and filter starts empty,
+// bloom filter need to be rebuilt from database in that case.
+func (f *MemoryFilter) EnableSnapshot(ctx context.Context, path string, interval time.Duration) {
+	logger := logging.FromContext(ctx).Named("bloomfilter_snapshot")
+
+	switch err := f.LoadSnapshot(path); {
+	case err == nil:
+		logger.Infof("loaded bloom filter snapshot from %s", path)
+	case errors.Is(err, os.ErrNotExist):
+		logger.Infof("no bloom filter snapshot in %s, start with empty filter", path)
+	default:
+		logger.Errorf("load bloom filter snapshot from %s failed, start with empty filter: %s", path, err.Error())
+	}
+
+	f.mu.Lock()
+	f.snapshotPath = path
+	f.mu.Unlock()
+
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := f.saveSnapshotIfOpen(); err != nil {
+					logger.Errorf("save bloom filter snapshot to %s failed: %s", path, err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// saveSnapshotIfOpen save snapshot unless filter is closed,
+// otherwise the empty filter after Close would overwrite the snapshot.
+func (f *MemoryFilter) saveSnapshotIfOpen() error {
+	f.snapshotMu.Lock()
+	defer f.snapshotMu.Unlock()
+
+	return f.saveSnapshotLocked()
+}
+
+// saveSnapshotLocked should be called with snapshotMu held
+func (f *MemoryFilter) saveSnapshotLocked() error {
+	f.mu.RLock()
+	path, closed := f.snapshotPath, f.closed
+	f.mu.RUnlock()
+
+	if path == "" || closed {
+		return nil
+	}
+
+	return f.SaveSnapshot(path)
+}
@@ -0,0 +1,74 @@
+package bloomfilter
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestMemoryFilter_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "bloomfilter.snapshot")
+
+	f := NewMemoryFilter(0.01, 100)
+	_ = f.Reserve(ctx, "key", 0.01, 100)
+	for i := 0; i < 50; i++ {
+		_ = f.Add(ctx, "key", strconv.Itoa(i))
+	}
+
+	f.EnableSnapshot(ctx, path, 0)
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	loaded := NewMemoryFilter(0.01, 100)
+	if err := loaded.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		if ok, _ := loaded.Exists(ctx, "key", strconv.Itoa(i)); !ok {
+			t.Fatalf("item %d not exist after load snapshot", i)
+		}
+	}
+
+	info, err := loaded.Info(ctx, "key")
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.Capacity != 100 || info.Items != 50 {
+		t.Errorf("expect capacity 100 and items 50, got %+v", info)
+	}
+
+	// closed filter should not overwrite snapshot with empty filter
+	if err := f.saveSnapshotIfOpen(); err != nil {
+		t.Fatalf("saveSnapshotIfOpen: %v", err)
+	}
+	if err := loaded.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if ok, _ := loaded.Exists(ctx, "key", "0"); !ok {
+		t.Error("snapshot is overwritten after Close")
+	}
+}
+
+func TestBloomLocations(t *testing.T) {
+	const m, k = 1000, 7
+
+	h1, h2 := bloomHash("item")
+	if h1 > 0xFFFFFFFF || h2 > 0xFFFFFFFF {
+		t.Fatalf("hash should be 32 bit, got %d %d", h1, h2)
+	}
+
+	locations := bloomLocations(h1, h2, m, k)
+	if len(locations) != k {
+		t.Fatalf("expect %d locations, got %d", k, len(locations))
+	}
+
+	for _, l := range locations {
+		if l >= m {
+			t.Errorf("location %d out of %d bits", l, m)
+		}
+	}
+}
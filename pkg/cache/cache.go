@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get when key is not in cache
+var ErrNotFound = errors.New("cache: key not found")
+
+// Cache is key-value store with expiration.
+// It is implemented by RedisCache and MemoryCache.
+type Cache interface {
+	// Get return ErrNotFound if key not exist or expired
+	Get(ctx context.Context, key string) (string, error)
+
+	// Set value with expiration, 0 expiration means never expire
+	Set(ctx context.Context, key string, value string, expiration time.Duration) error
+
+	// SetMany set all values with the same expiration.
+	// It saves round trips compare to calling Set one by one.
+	SetMany(ctx context.Context, values map[string]string, expiration time.Duration) error
+
+	// Del remove keys, key not exist is ignored
+	Del(ctx context.Context, keys ...string) error
+
+	// TTL return the remaining time to live of key,
+	// 0 means key never expire.
+	// ErrNotFound is returned if key not exist.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
+	// Close release the resource of cache
+	Close() error
+}
@@ -1,12 +1,28 @@
-// Package cache is the config that can use redis as cache
+// Package cache provides key-value cache backed by redis or local memory
 package cache
 
+import "github.com/TinyMurky/tinyurl/pkg/redisclient"
+
+// Backend is where the cache is stored
+type Backend string
+
+const (
+	// BackendRedis store cache in redis
+	BackendRedis Backend = "redis"
+	// BackendMemory store cache in memory of current process,
+	// it is not shared between instances.
+	BackendMemory Backend = "memory"
+)
+
 // Config is the config of cache
 type Config struct {
-	RedisConnectURL            string `env:"REDIS_CONNECT_URL, default=localhost:6379"`
-	RedisPassword              string `env:"REDIS_PASSWORD"`
-	RedisCacheDB               int    `env:"REDIS_CACHE_DB, default=0"`
-	RedisSerializationProtocol int    `env:"REDIS_SERIALIZATION_PROTOCAL, default=2"`
+	Backend      Backend `env:"CACHE_BACKEND, default=redis"`
+	Redis        redisclient.Config
+	RedisCacheDB int `env:"REDIS_CACHE_DB, default=0"`
+
+	// MemoryMaxEntries is the max number of keys in memory cache,
+	// least recently used key is evicted when it is full.
+	MemoryMaxEntries int `env:"MEMORY_CACHE_MAX_ENTRIES, default=100000"`
 }
 
 // CacheConfig return the config of cache
@@ -2,40 +2,30 @@ package cache
 
 import (
 	"context"
-
-	"github.com/redis/go-redis/v9"
+	"fmt"
+	"strings"
 
 	"github.com/TinyMurky/tinyurl/pkg/logging"
+	"github.com/TinyMurky/tinyurl/pkg/redisclient"
 )
 
-// Cache is the pool of database Conn
-type Cache struct {
-	RDB *redis.Client
-}
-
-// NewFromEnv sets up the redis cache connections using the configuration in the
+// NewFromEnv sets up the cache using the configuration in the
 // process's environment variables. This should be called just once per server
 // instance.
-func NewFromEnv(ctx context.Context, cfg *Config) (*Cache, error) {
+func NewFromEnv(ctx context.Context, cfg *Config) (Cache, error) {
 	logger := logging.FromContext(ctx)
 
-	rdbOpt := redis.Options{
-		Addr:     cfg.RedisConnectURL,
-		Password: cfg.RedisPassword,
-		DB:       cfg.RedisCacheDB,
-		Protocol: cfg.RedisSerializationProtocol,
+	switch Backend(strings.ToLower(strings.TrimSpace(string(cfg.Backend)))) {
+	case BackendRedis, "":
+		rdb, err := redisclient.New(ctx, &cfg.Redis, cfg.RedisCacheDB)
+		if err != nil {
+			return nil, fmt.Errorf("redisclient.New: %w", err)
+		}
+		return NewRedisCache(rdb), nil
+	case BackendMemory:
+		logger.Infof("Open memory cache with max entries: %d", cfg.MemoryMaxEntries)
+		return NewMemoryCache(cfg.MemoryMaxEntries), nil
+	default:
+		return nil, fmt.Errorf("unknown CACHE_BACKEND %q", cfg.Backend)
 	}
-
-	rdb := redis.NewClient(&rdbOpt)
-
-	logger.Infof("Open redis cache at URL: %s", rdbOpt.Addr)
-
-	return &Cache{
-		RDB: rdb,
-	}, nil
-}
-
-// Close will close connection with redis
-func (c *Cache) Close() error {
-	return c.RDB.Close()
 }
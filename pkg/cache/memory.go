@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache is LRU cache stored in memory of current process.
+// It is safe for concurrent use.
+type MemoryCache struct {
+	maxEntries int
+
+	// now can be replaced in test
+	now func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time // zero means never expire
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache create memory cache holding at most maxEntries keys,
+// maxEntries <= 0 means no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get value of key
+func (c *MemoryCache) Get(_ context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return entry.value, nil
+}
+
+// Set value of key
+func (c *MemoryCache) Set(_ context.Context, key string, value string, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = c.now().Add(expiration)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(elem)
+		return nil
+	}
+
+	elem := c.ll.PushFront(&memoryEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+	c.items[key] = elem
+
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+
+	return nil
+}
+
+// SetMany set values
+func (c *MemoryCache) SetMany(ctx context.Context, values map[string]string, expiration time.Duration) error {
+	for key, value := range values {
+		if err := c.Set(ctx, key, value, expiration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Del remove keys
+func (c *MemoryCache) Del(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.items[key]; ok {
+			c.removeElement(elem)
+		}
+	}
+
+	return nil
+}
+
+// TTL return the remaining time to live of key
+func (c *MemoryCache) TTL(_ context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	if !ok {
+		return 0, ErrNotFound
+	}
+
+	if entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+
+	return entry.expiresAt.Sub(c.now()), nil
+}
+
+// Close drop all keys
+func (c *MemoryCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+
+	return nil
+}
+
+// lookup should be called with lock held,
+// expired key is removed on lookup.
+func (c *MemoryCache) lookup(key string) (*memoryEntry, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return entry, true
+}
+
+// removeElement should be called with lock held
+func (c *MemoryCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_SetGet(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(0)
+
+	if _, err := c.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+
+	if err := c.Set(ctx, "a", "1", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, err := c.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if got != "1" {
+		t.Errorf("expect %q, got %q", "1", got)
+	}
+
+	if err := c.Del(ctx, "a", "not-exist"); err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+
+	if _, err := c.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expect ErrNotFound after Del, got %v", err)
+	}
+}
+
+func TestMemoryCache_Expiration(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewMemoryCache(0)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "a", "1", time.Second)
+	_ = c.Set(ctx, "b", "2", 0)
+
+	ttl, err := c.TTL(ctx, "a")
+	if err != nil || ttl != time.Second {
+		t.Errorf("TTL of a: expect 1s, got %v, %v", ttl, err)
+	}
+
+	ttl, err = c.TTL(ctx, "b")
+	if err != nil || ttl != 0 {
+		t.Errorf("TTL of b: expect 0, got %v, %v", ttl, err)
+	}
+
+	now = now.Add(time.Second)
+
+	if _, err := c.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expect a expired, got %v", err)
+	}
+
+	if _, err := c.TTL(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expect TTL of expired key ErrNotFound, got %v", err)
+	}
+
+	if _, err := c.Get(ctx, "b"); err != nil {
+		t.Errorf("expect b never expire, got %v", err)
+	}
+}
+
+func TestMemoryCache_EvictLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	_ = c.Set(ctx, "a", "1", 0)
+	_ = c.Set(ctx, "b", "2", 0)
+
+	// touch a, so b is least recently used
+	_, _ = c.Get(ctx, "a")
+
+	_ = c.Set(ctx, "c", "3", 0)
+
+	if _, err := c.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expect b evicted, got %v", err)
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if _, err := c.Get(ctx, key); err != nil {
+			t.Errorf("expect %s in cache, got %v", key, err)
+		}
+	}
+}
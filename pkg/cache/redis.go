@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is cache stored in redis.
+// RDB can be standalone, sentinel or cluster client.
+type RedisCache struct {
+	RDB redis.UniversalClient
+}
+
+var _ Cache = (*RedisCache)(nil)
+
+// NewRedisCache create cache on redis client
+func NewRedisCache(rdb redis.UniversalClient) *RedisCache {
+	return &RedisCache{
+		RDB: rdb,
+	}
+}
+
+// Get value of key
+func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
+	v, err := c.RDB.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return v, err
+}
+
+// Set value of key
+func (c *RedisCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	return c.RDB.Set(ctx, key, value, expiration).Err()
+}
+
+// SetMany set values in one pipeline.
+// In cluster mode the pipeline is split by hash slot by go-redis.
+func (c *RedisCache) SetMany(ctx context.Context, values map[string]string, expiration time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := c.RDB.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, expiration)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// Del remove keys
+func (c *RedisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.RDB.Del(ctx, keys...).Err()
+}
+
+// TTL return the remaining time to live of key
+func (c *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.RDB.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	switch ttl {
+	case -2:
+		// key not exist
+		return 0, ErrNotFound
+	case -1:
+		// key exist but has no expiration
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// Close will close connection with redis
+func (c *RedisCache) Close() error {
+	return c.RDB.Close()
+}
@@ -0,0 +1,238 @@
+// Package circuitbreaker stops calling a failing dependency for a while,
+// so caller can fall back instead of waiting for timeout on every request.
+//
+// The breaker has three states:
+//   - closed: all calls go through, consecutive failures are counted
+//   - open: all calls are rejected with ErrOpen until OpenTimeout passed
+//   - half-open: a few probe calls go through, success closes the breaker
+//     and failure opens it again
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// ErrOpen is returned when breaker reject the call
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of breaker
+type State int
+
+const (
+	// StateClosed let all calls through
+	StateClosed State = iota
+	// StateOpen reject all calls
+	StateOpen
+	// StateHalfOpen let probe calls through
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a circuit breaker. It is safe for concurrent use.
+type Breaker struct {
+	name string
+	cfg  Config
+
+	// onStateChange is called (without lock held) when state changed
+	onStateChange func(name string, from, to State)
+
+	// now can be replaced in test
+	now func() time.Time
+
+	mu               sync.Mutex
+	state            State
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenSuccess  int
+}
+
+// New create a circuit breaker, state change is logged by logger in ctx.
+func New(ctx context.Context, name string, cfg *Config) *Breaker {
+	logger := logging.FromContext(ctx).Named("circuitbreaker")
+
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 1
+	}
+
+	if c.HalfOpenMaxRequests <= 0 {
+		c.HalfOpenMaxRequests = 1
+	}
+
+	return &Breaker{
+		name: name,
+		cfg:  c,
+		onStateChange: func(name string, from, to State) {
+			logger.Warnw("circuit breaker state changed", "name", name, "from", from.String(), "to", to.String())
+		},
+		now:   time.Now,
+		state: StateClosed,
+	}
+}
+
+// Name return the name of breaker
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State return the current state of breaker
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Do runs fn if breaker allow it and record the result.
+// ErrOpen is returned without calling fn when breaker is open.
+//
+// fn should return nil for "expected" errors (ex: redis.Nil),
+// any other non nil error is counted as failure except context.Canceled,
+// which tells nothing about the health of dependency.
+func (b *Breaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrOpen
+	}
+
+	err := fn()
+
+	switch {
+	case err == nil:
+		b.record(outcomeSuccess)
+	case errors.Is(err, context.Canceled):
+		b.record(outcomeIgnored)
+	default:
+		b.record(outcomeFailure)
+	}
+
+	return err
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+
+	var from, to State
+	changed := false
+
+	defer func() {
+		b.mu.Unlock()
+		if changed {
+			b.onStateChange(b.name, from, to)
+		}
+	}()
+
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return false
+		}
+		from, to, changed = b.state, StateHalfOpen, true
+		b.state = StateHalfOpen
+		b.halfOpenInFlight = 0
+		b.halfOpenSuccess = 0
+		fallthrough
+	case StateHalfOpen:
+		if b.halfOpenInFlight >= b.cfg.HalfOpenMaxRequests {
+			return false
+		}
+		b.halfOpenInFlight++
+		return true
+	}
+
+	return false
+}
+
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored
+)
+
+func (b *Breaker) record(o outcome) {
+	b.mu.Lock()
+
+	var from, to State
+	changed := false
+
+	defer func() {
+		b.mu.Unlock()
+		if changed {
+			b.onStateChange(b.name, from, to)
+		}
+	}()
+
+	switch b.state {
+	case StateClosed:
+		switch o {
+		case outcomeSuccess:
+			b.failures = 0
+			return
+		case outcomeIgnored:
+			return
+		}
+
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			from, to, changed = b.state, StateOpen, true
+			b.open()
+		}
+	case StateHalfOpen:
+		switch o {
+		case outcomeIgnored:
+			// give the probe slot back
+			b.halfOpenInFlight--
+			return
+		case outcomeFailure:
+			from, to, changed = b.state, StateOpen, true
+			b.open()
+			return
+		}
+
+		b.halfOpenSuccess++
+		if b.halfOpenSuccess >= b.cfg.HalfOpenMaxRequests {
+			from, to, changed = b.state, StateClosed, true
+			b.state = StateClosed
+			b.failures = 0
+		}
+	case StateOpen:
+		// result of call that started before breaker opened, ignore it
+	}
+}
+
+// open should be called with lock held
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.failures = 0
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccess = 0
+}
@@ -0,0 +1,157 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(cfg Config) (*Breaker, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(context.Background(), "test", &cfg)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_OpenAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(Config{
+		FailureThreshold:    3,
+		OpenTimeout:         time.Second,
+		HalfOpenMaxRequests: 1,
+	})
+
+	errFail := errors.New("fail")
+
+	for i := 0; i < 3; i++ {
+		if err := b.Do(func() error { return errFail }); !errors.Is(err, errFail) {
+			t.Fatalf("call %d: expect errFail, got %v", i, err)
+		}
+	}
+
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("expect open, got %s", got)
+	}
+
+	called := false
+	err := b.Do(func() error {
+		called = true
+		return nil
+	})
+
+	if !errors.Is(err, ErrOpen) {
+		t.Errorf("expect ErrOpen, got %v", err)
+	}
+
+	if called {
+		t.Error("fn should not be called when breaker is open")
+	}
+}
+
+func TestBreaker_SuccessResetFailures(t *testing.T) {
+	b, _ := newTestBreaker(Config{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Second,
+	})
+
+	errFail := errors.New("fail")
+
+	_ = b.Do(func() error { return errFail })
+	_ = b.Do(func() error { return nil })
+	_ = b.Do(func() error { return errFail })
+
+	if got := b.State(); got != StateClosed {
+		t.Errorf("expect closed, got %s", got)
+	}
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	b, now := newTestBreaker(Config{
+		FailureThreshold:    1,
+		OpenTimeout:         time.Second,
+		HalfOpenMaxRequests: 1,
+	})
+
+	var changes []State
+	b.onStateChange = func(_ string, _, to State) {
+		changes = append(changes, to)
+	}
+
+	errFail := errors.New("fail")
+	_ = b.Do(func() error { return errFail })
+
+	*now = now.Add(time.Second)
+
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("expect half-open, got %s", got)
+	}
+
+	// failed probe open it again
+	_ = b.Do(func() error { return errFail })
+
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("expect open after failed probe, got %s", got)
+	}
+
+	*now = now.Add(time.Second)
+
+	// successful probe close it
+	if err := b.Do(func() error { return nil }); err != nil {
+		t.Fatalf("probe: unexpected error %v", err)
+	}
+
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("expect closed after successful probe, got %s", got)
+	}
+
+	want := []State{StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("state changes: expect %v, got %v", want, changes)
+	}
+
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("state change %d: expect %s, got %s", i, want[i], changes[i])
+		}
+	}
+}
+
+func TestBreaker_HalfOpenLimitProbe(t *testing.T) {
+	b, now := newTestBreaker(Config{
+		FailureThreshold:    1,
+		OpenTimeout:         time.Second,
+		HalfOpenMaxRequests: 1,
+	})
+
+	_ = b.Do(func() error { return errors.New("fail") })
+	*now = now.Add(time.Second)
+
+	// first probe is still running, second call is rejected
+	err := b.Do(func() error {
+		if err := b.Do(func() error { return nil }); !errors.Is(err, ErrOpen) {
+			t.Errorf("second probe: expect ErrOpen, got %v", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("first probe: unexpected error %v", err)
+	}
+}
+
+func TestBreaker_IgnoreCanceled(t *testing.T) {
+	b, _ := newTestBreaker(Config{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Second,
+	})
+
+	err := b.Do(func() error { return context.Canceled })
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect context.Canceled, got %v", err)
+	}
+
+	if got := b.State(); got != StateClosed {
+		t.Errorf("expect closed, got %s", got)
+	}
+}
@@ -0,0 +1,23 @@
+package circuitbreaker
+
+import "time"
+
+// Config is the config of circuit breaker
+type Config struct {
+	// FailureThreshold is the number of consecutive failures
+	// that will open the breaker
+	FailureThreshold int `env:"CIRCUIT_BREAKER_FAILURE_THRESHOLD, default=5"`
+
+	// OpenTimeout is how long the breaker stay open
+	// before it let probe requests through (half-open)
+	OpenTimeout time.Duration `env:"CIRCUIT_BREAKER_OPEN_TIMEOUT, default=10s"`
+
+	// HalfOpenMaxRequests is the number of probe requests allowed in half-open.
+	// The breaker closes after all of them succeed.
+	HalfOpenMaxRequests int `env:"CIRCUIT_BREAKER_HALF_OPEN_MAX_REQUESTS, default=1"`
+}
+
+// CircuitBreakerConfig return the config of circuit breaker
+func (c *Config) CircuitBreakerConfig() *Config {
+	return c
+}
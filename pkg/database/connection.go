@@ -29,6 +29,11 @@ func NewFromEnv(ctx context.Context, cfg *Config) (*DB, error) {
 		return nil, fmt.Errorf("fail to open sqlite connection pool: %w", err)
 	}
 
+	if err := pool.PingContext(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("fail to ping sqlite: %w", err)
+	}
+
 	newDB := DB{
 		Pool: pool,
 	}
@@ -21,6 +21,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 var (
@@ -31,6 +34,22 @@ var (
 	ErrKeyConflict = errors.New("key conflict")
 )
 
+// IsKeyConflict report whether err is unique or primary key
+// constraint violation returned by sqlite
+func IsKeyConflict(err error) bool {
+	if errors.Is(err, ErrKeyConflict) {
+		return true
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
 // InTx runs the given function f within a transaction with the provided
 // sql TxOption.
 func (db *DB) InTx(ctx context.Context, opts *sql.TxOptions, f func(tx *sql.Tx) error) error {
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestIsKeyConflict(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.ExecContext(ctx, `CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT NOT NULL UNIQUE)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	if _, err := pool.ExecContext(ctx, `INSERT INTO t (id, v) VALUES (1, 'a')`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	_, err = pool.ExecContext(ctx, `INSERT INTO t (id, v) VALUES (2, 'a')`)
+	if !IsKeyConflict(err) {
+		t.Errorf("unique violation: expect key conflict, got %v", err)
+	}
+
+	_, err = pool.ExecContext(ctx, `INSERT INTO t (id, v) VALUES (1, 'b')`)
+	if !IsKeyConflict(err) {
+		t.Errorf("primary key violation: expect key conflict, got %v", err)
+	}
+
+	if IsKeyConflict(errors.New("other")) || IsKeyConflict(nil) {
+		t.Error("other error should not be key conflict")
+	}
+}
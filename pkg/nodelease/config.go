@@ -0,0 +1,54 @@
+// Package nodelease leases a unique snowflake node ID from redis or database,
+// so replicas do not need a hand-assigned node ID.
+package nodelease
+
+import (
+	"strings"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/pkg/redisclient"
+)
+
+// Backend is where leases are stored
+type Backend string
+
+const (
+	// BackendNone disable leasing, node ID is read from config
+	BackendNone Backend = ""
+	// BackendRedis store lease as key with TTL in redis
+	BackendRedis Backend = "redis"
+	// BackendDatabase store lease in node_leases table
+	BackendDatabase Backend = "database"
+)
+
+// Config is the config of node ID lease
+type Config struct {
+	Backend Backend `env:"NODE_LEASE_BACKEND"`
+	Redis   redisclient.Config
+	RedisDB int `env:"REDIS_NODE_LEASE_DB, default=0"`
+
+	// TTL is how long a lease lives without heartbeat
+	TTL time.Duration `env:"NODE_LEASE_TTL, default=30s"`
+
+	// HeartbeatInterval should be well below TTL,
+	// so a few failed heartbeats do not lose the lease
+	HeartbeatInterval time.Duration `env:"NODE_LEASE_HEARTBEAT_INTERVAL, default=10s"`
+
+	// MaxNodeID is the largest node ID to lease, 1023 for 10 node bits of snowflake
+	MaxNodeID int64 `env:"NODE_LEASE_MAX_NODE_ID, default=1023"`
+}
+
+// NodeLeaseConfig return the config of node ID lease
+func (c *Config) NodeLeaseConfig() *Config {
+	return c
+}
+
+// BackendType return normalized backend
+func (c *Config) BackendType() Backend {
+	return Backend(strings.ToLower(strings.TrimSpace(string(c.Backend))))
+}
+
+// Enabled tells if node ID should be leased
+func (c *Config) Enabled() bool {
+	return c.BackendType() != BackendNone
+}
@@ -0,0 +1,39 @@
+package nodelease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TinyMurky/tinyurl/pkg/database"
+	"github.com/TinyMurky/tinyurl/pkg/redisclient"
+)
+
+// NewFromEnv create store of cfg.Backend and acquire a lease.
+// db is required by database backend.
+func NewFromEnv(ctx context.Context, cfg *Config, db *database.DB) (*Lease, error) {
+	var store Store
+
+	switch cfg.BackendType() {
+	case BackendRedis:
+		rdb, err := redisclient.New(ctx, &cfg.Redis, cfg.RedisDB)
+		if err != nil {
+			return nil, fmt.Errorf("redisclient.New: %w", err)
+		}
+		store = NewRedisStore(rdb)
+	case BackendDatabase:
+		if db == nil {
+			return nil, errors.New("NODE_LEASE_BACKEND=database need database")
+		}
+		store = NewDatabaseStore(db)
+	default:
+		return nil, fmt.Errorf("unknown NODE_LEASE_BACKEND %q", cfg.Backend)
+	}
+
+	lease, err := Acquire(ctx, store, cfg)
+	if err != nil {
+		return nil, errors.Join(err, store.Close())
+	}
+
+	return lease, nil
+}
@@ -0,0 +1,93 @@
+package nodelease
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/pkg/database"
+)
+
+// DatabaseStore keep leases in node_leases table,
+// expires_at is unix milliseconds.
+//
+// Database is shared with the server, so it is not closed by Close.
+type DatabaseStore struct {
+	db  *database.DB
+	now func() time.Time
+}
+
+var _ Store = (*DatabaseStore)(nil)
+
+// NewDatabaseStore create a new DatabaseStore
+func NewDatabaseStore(db *database.DB) *DatabaseStore {
+	return &DatabaseStore{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+// Acquire insert lease, or take over the expired one
+func (s *DatabaseStore) Acquire(ctx context.Context, nodeID int64, owner string, ttl time.Duration) (bool, error) {
+	now := s.now()
+
+	query := `
+		INSERT INTO node_leases (node_id, owner, expires_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (node_id) DO UPDATE
+		SET owner = excluded.owner, expires_at = excluded.expires_at
+		WHERE node_leases.expires_at <= ?;
+	`
+
+	result, err := s.db.Pool.ExecContext(ctx, query, nodeID, owner, now.Add(ttl).UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("acquire lease error: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// Renew extend lease if it is held by owner
+func (s *DatabaseStore) Renew(ctx context.Context, nodeID int64, owner string, ttl time.Duration) (bool, error) {
+	query := `
+		UPDATE node_leases
+		SET expires_at = ?
+		WHERE node_id = ? AND owner = ?;
+	`
+
+	result, err := s.db.Pool.ExecContext(ctx, query, s.now().Add(ttl).UnixMilli(), nodeID, owner)
+	if err != nil {
+		return false, fmt.Errorf("renew lease error: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// Release delete lease if it is held by owner
+func (s *DatabaseStore) Release(ctx context.Context, nodeID int64, owner string) error {
+	query := `
+		DELETE FROM node_leases
+		WHERE node_id = ? AND owner = ?;
+	`
+
+	if _, err := s.db.Pool.ExecContext(ctx, query, nodeID, owner); err != nil {
+		return fmt.Errorf("release lease error: %w", err)
+	}
+
+	return nil
+}
+
+// Close do nothing, database is closed by its owner
+func (s *DatabaseStore) Close() error {
+	return nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rows affected error: %w", err)
+	}
+
+	return affected > 0, nil
+}
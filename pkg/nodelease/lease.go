@@ -0,0 +1,217 @@
+package nodelease
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// releaseTimeout bound the release on shutdown, lease expires by itself anyway
+const releaseTimeout = 5 * time.Second
+
+// ErrNoNodeAvailable is returned when every node ID is leased by others
+var ErrNoNodeAvailable = errors.New("nodelease: no node ID available")
+
+// ErrLeaseLost is returned when lease is expired or taken by others
+var ErrLeaseLost = errors.New("nodelease: lease lost")
+
+// Store keep leases, each node ID has at most one unexpired owner.
+type Store interface {
+	// Acquire lease nodeID for owner if it is free or expired,
+	// false is returned if it is held by others.
+	Acquire(ctx context.Context, nodeID int64, owner string, ttl time.Duration) (bool, error)
+
+	// Renew extend lease of owner, false is returned if owner does not hold it anymore.
+	Renew(ctx context.Context, nodeID int64, owner string, ttl time.Duration) (bool, error)
+
+	// Release remove lease if it is held by owner
+	Release(ctx context.Context, nodeID int64, owner string) error
+
+	// Close release the resource of store
+	Close() error
+}
+
+// Lease is a node ID held by current process.
+//
+// It is renewed by heartbeat, and becomes invalid when it is taken by others
+// or when heartbeat fails until TTL passes, so two processes never use the
+// same node ID at the same time.
+type Lease struct {
+	store  Store
+	cfg    Config
+	nodeID int64
+	owner  string
+	now    func() time.Time
+
+	mu        sync.Mutex
+	expiresAt time.Time
+	lost      bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Acquire lease the first free node ID, starting from a random one
+// so replicas starting together do not race for the same ID.
+// Heartbeat runs until ctx is done or Close is called.
+func Acquire(ctx context.Context, store Store, cfg *Config) (*Lease, error) {
+	if cfg.MaxNodeID < 0 {
+		return nil, fmt.Errorf("invalid NODE_LEASE_MAX_NODE_ID %d", cfg.MaxNodeID)
+	}
+
+	if cfg.TTL <= 0 || cfg.HeartbeatInterval <= 0 || cfg.HeartbeatInterval >= cfg.TTL {
+		return nil, fmt.Errorf("NODE_LEASE_HEARTBEAT_INTERVAL %s must be positive and less than NODE_LEASE_TTL %s",
+			cfg.HeartbeatInterval, cfg.TTL)
+	}
+
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Lease{
+		store: store,
+		cfg:   *cfg,
+		owner: owner,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	total := cfg.MaxNodeID + 1
+	start := mathrand.Int64N(total)
+
+	for i := range total {
+		nodeID := (start + i) % total
+
+		// expiry is counted from before the call, so local view never outlives the lease
+		expiresAt := l.now().Add(cfg.TTL)
+
+		ok, err := store.Acquire(ctx, nodeID, owner, cfg.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("acquire node %d: %w", nodeID, err)
+		}
+
+		if ok {
+			l.nodeID = nodeID
+			l.expiresAt = expiresAt
+			go l.heartbeat(ctx)
+			return l, nil
+		}
+	}
+
+	return nil, ErrNoNodeAvailable
+}
+
+// NodeID return the leased node ID
+func (l *Lease) NodeID() int64 {
+	return l.nodeID
+}
+
+// Owner return the unique owner name of current process
+func (l *Lease) Owner() string {
+	return l.owner
+}
+
+// Valid return ErrLeaseLost if node ID may be used by others now
+func (l *Lease) Valid() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.lost || !l.now().Before(l.expiresAt) {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+// Close stop heartbeat and release the lease, store is closed too.
+func (l *Lease) Close(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+
+	l.mu.Lock()
+	l.lost = true
+	l.mu.Unlock()
+
+	// ctx is usually done on shutdown, release still needs a chance to run
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+
+	err := l.store.Release(ctx, l.nodeID, l.owner)
+	if err != nil {
+		err = fmt.Errorf("release node %d: %w", l.nodeID, err)
+	}
+
+	return errors.Join(err, l.store.Close())
+}
+
+func (l *Lease) heartbeat(ctx context.Context) {
+	defer close(l.done)
+
+	logger := logging.FromContext(ctx).Named("nodelease")
+
+	ticker := time.NewTicker(l.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.renew(ctx); err != nil {
+				logger.Errorw("renew node lease", "node_id", l.nodeID, "error", err)
+			}
+		}
+	}
+}
+
+// renew extend lease, lease is lost for good once others hold it.
+func (l *Lease) renew(ctx context.Context) error {
+	expiresAt := l.now().Add(l.cfg.TTL)
+
+	ok, err := l.store.Renew(ctx, l.nodeID, l.owner, l.cfg.TTL)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !ok {
+		l.lost = true
+		return ErrLeaseLost
+	}
+
+	if !l.lost {
+		l.expiresAt = expiresAt
+	}
+
+	return nil
+}
+
+// newOwner create owner name unique among processes
+func newOwner() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate owner: %w", err)
+	}
+
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,109 @@
+package nodelease
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TinyMurky/tinyurl/pkg/database"
+)
+
+func newTestStore(t *testing.T) *DatabaseStore {
+	t.Helper()
+
+	pool, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	// every connection of :memory: is a different database
+	pool.SetMaxOpenConns(1)
+	t.Cleanup(func() { pool.Close() })
+
+	query := `CREATE TABLE node_leases (node_id INTEGER PRIMARY KEY, owner TEXT NOT NULL, expires_at INTEGER NOT NULL)`
+	if _, err := pool.Exec(query); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	return NewDatabaseStore(&database.DB{Pool: pool})
+}
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	cfg := &Config{TTL: time.Minute, HeartbeatInterval: time.Second, MaxNodeID: 1}
+
+	a, err := Acquire(ctx, store, cfg)
+	if err != nil {
+		t.Fatalf("Acquire a: %v", err)
+	}
+
+	b, err := Acquire(ctx, store, cfg)
+	if err != nil {
+		t.Fatalf("Acquire b: %v", err)
+	}
+
+	if a.NodeID() == b.NodeID() {
+		t.Fatalf("both leases got node %d", a.NodeID())
+	}
+
+	if _, err := Acquire(ctx, store, cfg); !errors.Is(err, ErrNoNodeAvailable) {
+		t.Fatalf("expect ErrNoNodeAvailable, got %v", err)
+	}
+
+	if err := a.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if err := a.Valid(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("closed lease should be invalid, got %v", err)
+	}
+
+	c, err := Acquire(ctx, store, cfg)
+	if err != nil {
+		t.Fatalf("released node should be acquired again: %v", err)
+	}
+
+	if c.NodeID() != a.NodeID() {
+		t.Errorf("expect released node %d, got %d", a.NodeID(), c.NodeID())
+	}
+}
+
+func TestLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	cfg := &Config{TTL: time.Minute, HeartbeatInterval: time.Second, MaxNodeID: 0}
+
+	l, err := Acquire(ctx, store, cfg)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer l.Close(ctx)
+
+	if err := l.Valid(); err != nil {
+		t.Fatalf("new lease should be valid: %v", err)
+	}
+
+	// lease expired without heartbeat
+	now := time.Now()
+	l.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+	if err := l.Valid(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("expired lease should be invalid, got %v", err)
+	}
+
+	// and taken by others
+	store.now = l.now
+	if ok, err := store.Acquire(ctx, l.NodeID(), "other", cfg.TTL); !ok || err != nil {
+		t.Fatalf("expired lease should be taken over: %v, %v", ok, err)
+	}
+
+	if err := l.renew(ctx); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("renew should find lease lost, got %v", err)
+	}
+
+	if err := l.Valid(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("lost lease should stay invalid, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package nodelease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewScript extend TTL only if lease is still held by owner
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript delete lease only if it is held by owner
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisStore keep each lease as a key expired by redis
+type RedisStore struct {
+	RDB redis.UniversalClient
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore create a new RedisStore
+func NewRedisStore(rdb redis.UniversalClient) *RedisStore {
+	return &RedisStore{
+		RDB: rdb,
+	}
+}
+
+// Acquire lease nodeID with SET NX
+func (s *RedisStore) Acquire(ctx context.Context, nodeID int64, owner string, ttl time.Duration) (bool, error) {
+	err := s.RDB.SetArgs(ctx, genLeaseKey(nodeID), owner, redis.SetArgs{
+		Mode: "NX",
+		TTL:  ttl,
+	}).Err()
+
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("SET NX: %w", err)
+	}
+
+	return true, nil
+}
+
+// Renew extend lease with PEXPIRE if it is held by owner
+func (s *RedisStore) Renew(ctx context.Context, nodeID int64, owner string, ttl time.Duration) (bool, error) {
+	n, err := renewScript.Run(ctx, s.RDB, []string{genLeaseKey(nodeID)}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("renew script: %w", err)
+	}
+
+	return n == 1, nil
+}
+
+// Release delete lease if it is held by owner
+func (s *RedisStore) Release(ctx context.Context, nodeID int64, owner string) error {
+	if err := releaseScript.Run(ctx, s.RDB, []string{genLeaseKey(nodeID)}, owner).Err(); err != nil {
+		return fmt.Errorf("release script: %w", err)
+	}
+
+	return nil
+}
+
+// Close redis connection
+func (s *RedisStore) Close() error {
+	return s.RDB.Close()
+}
+
+func genLeaseKey(nodeID int64) string {
+	return fmt.Sprintf("nodelease:node:%d", nodeID)
+}
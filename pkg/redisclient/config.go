@@ -0,0 +1,163 @@
+// Package redisclient builds redis.UniversalClient for standalone, sentinel
+// or cluster deployment. It is shared by pkg/cache and pkg/bloomfilter.
+package redisclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Mode is the deployment topology of redis
+type Mode string
+
+const (
+	// ModeStandalone connect to single redis node
+	ModeStandalone Mode = "standalone"
+	// ModeSentinel connect to redis master through sentinel
+	ModeSentinel Mode = "sentinel"
+	// ModeCluster connect to redis cluster
+	ModeCluster Mode = "cluster"
+)
+
+// Config is the connection config of redis.
+// Logical DB is not part of it, each user of redis provides its own.
+type Config struct {
+	// REDIS_CONNECT_URL is comma separated "host:port" list.
+	// In sentinel mode they are the addresses of sentinels,
+	// in cluster mode they are the seed nodes of cluster.
+	RedisConnectURL            []string `env:"REDIS_CONNECT_URL, default=localhost:6379"`
+	RedisMode                  Mode     `env:"REDIS_MODE, default=standalone"`
+	RedisUsername              string   `env:"REDIS_USERNAME"`
+	RedisPassword              string   `env:"REDIS_PASSWORD"`
+	RedisSerializationProtocol int      `env:"REDIS_SERIALIZATION_PROTOCAL, default=2"`
+
+	RedisSentinelMasterName string `env:"REDIS_SENTINEL_MASTER_NAME"`
+	RedisSentinelUsername   string `env:"REDIS_SENTINEL_USERNAME"`
+	RedisSentinelPassword   string `env:"REDIS_SENTINEL_PASSWORD"`
+
+	RedisTLSEnabled            bool   `env:"REDIS_TLS_ENABLED, default=false"`
+	RedisTLSServerName         string `env:"REDIS_TLS_SERVER_NAME"`
+	RedisTLSCAFile             string `env:"REDIS_TLS_CA_FILE"`
+	RedisTLSCertFile           string `env:"REDIS_TLS_CERT_FILE"`
+	RedisTLSKeyFile            string `env:"REDIS_TLS_KEY_FILE"`
+	RedisTLSInsecureSkipVerify bool   `env:"REDIS_TLS_INSECURE_SKIP_VERIFY, default=false"`
+
+	// 0 means use the default of go-redis
+	RedisPoolSize     int           `env:"REDIS_POOL_SIZE, default=0"`
+	RedisMinIdleConns int           `env:"REDIS_MIN_IDLE_CONNS, default=0"`
+	RedisMaxRetries   int           `env:"REDIS_MAX_RETRIES, default=0"`
+	RedisPoolTimeout  time.Duration `env:"REDIS_POOL_TIMEOUT, default=0s"`
+	RedisDialTimeout  time.Duration `env:"REDIS_DIAL_TIMEOUT, default=0s"`
+	RedisReadTimeout  time.Duration `env:"REDIS_READ_TIMEOUT, default=0s"`
+	RedisWriteTimeout time.Duration `env:"REDIS_WRITE_TIMEOUT, default=0s"`
+}
+
+// IsCluster check if redis is running in cluster mode
+func (c *Config) IsCluster() bool {
+	return c.mode() == ModeCluster
+}
+
+// ToUniversalOptions transfer config to redis.UniversalOptions.
+// db is ignored in cluster mode because cluster only has DB 0.
+func (c *Config) ToUniversalOptions(db int) (*redis.UniversalOptions, error) {
+	addrs := make([]string, 0, len(c.RedisConnectURL))
+	for _, addr := range c.RedisConnectURL {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("REDIS_CONNECT_URL is empty")
+	}
+
+	opt := &redis.UniversalOptions{
+		Addrs:        addrs,
+		DB:           db,
+		Protocol:     c.RedisSerializationProtocol,
+		Username:     c.RedisUsername,
+		Password:     c.RedisPassword,
+		PoolSize:     c.RedisPoolSize,
+		MinIdleConns: c.RedisMinIdleConns,
+		MaxRetries:   c.RedisMaxRetries,
+		PoolTimeout:  c.RedisPoolTimeout,
+		DialTimeout:  c.RedisDialTimeout,
+		ReadTimeout:  c.RedisReadTimeout,
+		WriteTimeout: c.RedisWriteTimeout,
+	}
+
+	switch c.mode() {
+	case ModeStandalone:
+		if len(addrs) > 1 {
+			return nil, fmt.Errorf("standalone mode only accept one address, got %d", len(addrs))
+		}
+	case ModeSentinel:
+		if c.RedisSentinelMasterName == "" {
+			return nil, fmt.Errorf("REDIS_SENTINEL_MASTER_NAME is required in sentinel mode")
+		}
+		opt.MasterName = c.RedisSentinelMasterName
+		opt.SentinelUsername = c.RedisSentinelUsername
+		opt.SentinelPassword = c.RedisSentinelPassword
+	case ModeCluster:
+		opt.DB = 0
+		opt.IsClusterMode = true
+	default:
+		return nil, fmt.Errorf("unknown REDIS_MODE %q", c.RedisMode)
+	}
+
+	if c.RedisTLSEnabled {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return nil, fmt.Errorf("redis tls config: %w", err)
+		}
+		opt.TLSConfig = tlsConfig
+	}
+
+	return opt, nil
+}
+
+func (c *Config) mode() Mode {
+	mode := Mode(strings.ToLower(strings.TrimSpace(string(c.RedisMode))))
+	if mode == "" {
+		return ModeStandalone
+	}
+	return mode
+}
+
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.RedisTLSServerName,
+		InsecureSkipVerify: c.RedisTLSInsecureSkipVerify, //nolint:gosec // opt-in by config
+	}
+
+	if c.RedisTLSCAFile != "" {
+		pem, err := os.ReadFile(c.RedisTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in ca file %q", c.RedisTLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.RedisTLSCertFile != "" || c.RedisTLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.RedisTLSCertFile, c.RedisTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,120 @@
+package redisclient
+
+import (
+	"testing"
+)
+
+func TestToUniversalOptions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        Config
+		db            int
+		wantErr       bool
+		wantDB        int
+		wantMaster    string
+		wantIsCluster bool
+	}{
+		{
+			name: "standalone",
+			config: Config{
+				RedisConnectURL: []string{"localhost:6379"},
+				RedisMode:       ModeStandalone,
+			},
+			db:     1,
+			wantDB: 1,
+		},
+		{
+			name: "empty mode is standalone",
+			config: Config{
+				RedisConnectURL: []string{"localhost:6379"},
+			},
+			db:     2,
+			wantDB: 2,
+		},
+		{
+			name: "standalone with many address",
+			config: Config{
+				RedisConnectURL: []string{"a:6379", "b:6379"},
+				RedisMode:       ModeStandalone,
+			},
+			wantErr: true,
+		},
+		{
+			name: "sentinel",
+			config: Config{
+				RedisConnectURL:         []string{"a:26379", " b:26379 "},
+				RedisMode:               ModeSentinel,
+				RedisSentinelMasterName: "mymaster",
+			},
+			db:         1,
+			wantDB:     1,
+			wantMaster: "mymaster",
+		},
+		{
+			name: "sentinel without master name",
+			config: Config{
+				RedisConnectURL: []string{"a:26379"},
+				RedisMode:       ModeSentinel,
+			},
+			wantErr: true,
+		},
+		{
+			name: "cluster ignore db",
+			config: Config{
+				RedisConnectURL: []string{"a:6379", "b:6379"},
+				RedisMode:       "CLUSTER",
+			},
+			db:            1,
+			wantDB:        0,
+			wantIsCluster: true,
+		},
+		{
+			name: "unknown mode",
+			config: Config{
+				RedisConnectURL: []string{"a:6379"},
+				RedisMode:       "ring",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "empty address",
+			config:  Config{RedisConnectURL: []string{" "}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opt, err := tc.config.ToUniversalOptions(tc.db)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expect error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if opt.DB != tc.wantDB {
+				t.Errorf("DB: expect %d, got %d", tc.wantDB, opt.DB)
+			}
+
+			if opt.MasterName != tc.wantMaster {
+				t.Errorf("MasterName: expect %q, got %q", tc.wantMaster, opt.MasterName)
+			}
+
+			if opt.IsClusterMode != tc.wantIsCluster {
+				t.Errorf("IsClusterMode: expect %v, got %v", tc.wantIsCluster, opt.IsClusterMode)
+			}
+
+			for _, addr := range opt.Addrs {
+				if addr == "" || addr[0] == ' ' || addr[len(addr)-1] == ' ' {
+					t.Errorf("address %q is not trimmed", addr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package redisclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/TinyMurky/tinyurl/pkg/logging"
+)
+
+// New create redis.UniversalClient by config.
+// The type of client depends on REDIS_MODE:
+//   - standalone: *redis.Client
+//   - sentinel: *redis.Client that talks to master through sentinel
+//   - cluster: *redis.ClusterClient
+//
+// Redis is pinged once, so unreachable redis is reported here
+// instead of on the first request.
+func New(ctx context.Context, cfg *Config, db int) (redis.UniversalClient, error) {
+	logger := logging.FromContext(ctx)
+
+	opt, err := cfg.ToUniversalOptions(db)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis config: %w", err)
+	}
+
+	if cfg.IsCluster() && db != 0 {
+		logger.Warnf("redis cluster only support DB 0, DB %d is ignored", db)
+	}
+
+	logger.Infof("Open redis in %s mode at URL: %v", cfg.mode(), opt.Addrs)
+
+	rdb := redis.NewUniversalClient(opt)
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("ping redis at %v: %w", opt.Addrs, err)
+	}
+
+	return rdb, nil
+}
@@ -0,0 +1,17 @@
+package retry
+
+import "time"
+
+// Config is the config of retry with exponential backoff.
+// Env names are relative, user of Config give them a prefix,
+// ex: `env:", prefix=STARTUP_RETRY_"`.
+type Config struct {
+	// MaxAttempts is the number of calls including the first one,
+	// value <= 1 means no retry
+	MaxAttempts int `env:"MAX_ATTEMPTS, default=5"`
+
+	// InitialBackoff is the wait before the second call,
+	// it is doubled after every failure up to MaxBackoff
+	InitialBackoff time.Duration `env:"INITIAL_BACKOFF, default=500ms"`
+	MaxBackoff     time.Duration `env:"MAX_BACKOFF, default=10s"`
+}
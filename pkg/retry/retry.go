@@ -0,0 +1,45 @@
+// Package retry calls a function again with exponential backoff until
+// it succeeds, used to wait for dependencies (database, redis) on start up.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Do call fn until it returns nil, cfg.MaxAttempts is reached or ctx is done.
+// It returns the number of calls and the last error.
+func Do(ctx context.Context, cfg *Config, fn func(ctx context.Context) error) (int, error) {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	maxAttempts := max(c.MaxAttempts, 1)
+	backoff := c.InitialBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return attempt, nil
+		}
+
+		if attempt >= maxAttempts {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, fmt.Errorf("%w (stopped retry: %w)", err, ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if c.MaxBackoff > 0 && backoff > c.MaxBackoff {
+			backoff = c.MaxBackoff
+		}
+	}
+}
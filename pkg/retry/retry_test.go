@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+
+	errFail := errors.New("fail")
+
+	t.Run("succeed after retry", func(t *testing.T) {
+		calls := 0
+		attempts, err := Do(ctx, cfg, func(context.Context) error {
+			calls++
+			if calls < 2 {
+				return errFail
+			}
+			return nil
+		})
+
+		if err != nil || attempts != 2 {
+			t.Errorf("expect 2 attempts without error, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		attempts, err := Do(ctx, cfg, func(context.Context) error {
+			return errFail
+		})
+
+		if !errors.Is(err, errFail) || attempts != 3 {
+			t.Errorf("expect 3 attempts with errFail, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("stop when ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		attempts, err := Do(ctx, &Config{MaxAttempts: 3, InitialBackoff: time.Hour}, func(context.Context) error {
+			return errFail
+		})
+
+		if !errors.Is(err, errFail) || !errors.Is(err, context.Canceled) || attempts != 1 {
+			t.Errorf("expect 1 attempt with errFail and context.Canceled, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("nil config call once", func(t *testing.T) {
+		attempts, _ := Do(ctx, nil, func(context.Context) error {
+			return errFail
+		})
+
+		if attempts != 1 {
+			t.Errorf("expect 1 attempt, got %d", attempts)
+		}
+	})
+}